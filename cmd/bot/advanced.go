@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// Advanced exchanges the nine of trumps, announces marriages, closes the deck
+// when its sure tricks are enough to win, leads low cards while the deck is
+// open and takes only the tricks which are worth it. When the deck is empty
+// it knows both hands and plays perfectly.
+type Advanced struct{}
+
+// Act implements Strategy.
+func (s *Advanced) Act(v *View) Action {
+	if e, ok := v.Endgame(); ok {
+		if solution := e.Solve(); solution.Stop {
+			return Action{Type: Stop}
+		} else if solution.Card != sixtysix.NoCard {
+			return Action{Type: Play, Card: solution.Card}
+		}
+	}
+
+	cards := v.LegalCards()
+	if v.Table != sixtysix.NoCard {
+		return Action{Type: Play, Card: s.respond(v, cards)}
+	}
+
+	if v.CanStopAndWin() {
+		return Action{Type: Stop}
+	}
+	if v.CanExchange() {
+		return Action{Type: Exchange}
+	}
+	if v.CanClose() && v.Score+sureClosedPoints(v) >= sixtysix.WinningScore {
+		return Action{Type: Close}
+	}
+	if card := bestMarriage(v.Hand, v.Trump); card != sixtysix.NoCard {
+		return Action{Type: Marriage, Card: card}
+	}
+	return Action{Type: Play, Card: s.lead(v, cards)}
+}
+
+// lead returns the card to start the trick with.
+func (s *Advanced) lead(v *View, cards []deck.Card) deck.Card {
+	if v.IsStrict() {
+		var safe []deck.Card
+		for _, card := range cards {
+			if v.IsSafeToLead(card) {
+				safe = append(safe, card)
+			}
+		}
+		if len(safe) != 0 {
+			return highest(safe)
+		}
+	}
+	return cheapest(v, cards)
+}
+
+// respond returns the card to play against the card on the table.
+func (s *Advanced) respond(v *View, cards []deck.Card) deck.Card {
+	var sameSuit, trumps []deck.Card
+	for _, card := range cards {
+		if !sixtysix.Beats(card, v.Table, v.Trump) {
+			continue
+		}
+		if deck.AreTheSameSuit(card, v.Table) {
+			sameSuit = append(sameSuit, card)
+		} else {
+			trumps = append(trumps, card)
+		}
+	}
+
+	if v.IsStrict() {
+		if winners := append(sameSuit, trumps...); len(winners) != 0 {
+			return lowest(winners)
+		}
+		return cheapest(v, cards)
+	}
+
+	if len(sameSuit) != 0 {
+		return highest(sameSuit)
+	}
+	tablePts := v.Table.Points()
+	if len(trumps) != 0 {
+		trump := lowest(trumps)
+		if tablePts >= 10 || v.Score+tablePts+trump.Points() >= sixtysix.WinningScore {
+			return trump
+		}
+	}
+	return cheapest(v, cards)
+}
+
+// sureClosedPoints returns how many points the player would surely win if he closed
+// the deck now: the points from the marriages he can announce and from the cards which
+// will win their tricks whatever the opponent has. The points of the cards
+// of the opponent aren't counted.
+func sureClosedPoints(v *View) int {
+	unseen := v.Unseen()
+	var trumps []deck.Card
+	for _, card := range unseen {
+		if sixtysix.IsTrump(card, v.Trump) {
+			trumps = append(trumps, card)
+		}
+	}
+
+	pts, winners := 0, 0
+	for _, card := range v.MyCards() {
+		if card.Rank() == deck.Queen && v.HasWonTrick[v.Seat] {
+			pts += sixtysix.MarriagePoints(v.Hand, card, sixtysix.NoCard, v.Trump)
+		}
+		if sixtysix.IsTrump(card, v.Trump) && isHighest(card, unseen) {
+			pts += card.Points()
+			winners++
+		}
+	}
+	if winners < len(trumps) {
+		return pts // the opponent may trump the other cards
+	}
+
+	for _, card := range v.MyCards() {
+		if !sixtysix.IsTrump(card, v.Trump) && isHighest(card, unseen) {
+			pts += card.Points()
+		}
+	}
+	return pts
+}
+
+// isHighest returns true if none of cards beats card when it is led.
+func isHighest(card deck.Card, cards []deck.Card) bool {
+	for _, c := range cards {
+		if deck.AreTheSameSuit(c, card) && deck.HasHigherRank(c, card) {
+			return false
+		}
+	}
+	return true
+}
+
+// lowest returns the card with the least points.
+func lowest(cards []deck.Card) deck.Card {
+	return cards[findLowestRank(cards)-1]
+}
+
+// highest returns the card with the most points.
+func highest(cards []deck.Card) deck.Card {
+	best := cards[0]
+	for _, card := range cards[1:] {
+		if card.Points() > best.Points() {
+			best = card
+		}
+	}
+	return best
+}
+
+// cheapest returns the card which is the least pity to lose:
+// the lowest one which is neither a trump nor part of a marriage if there is such.
+func cheapest(v *View, cards []deck.Card) deck.Card {
+	var spare []deck.Card
+	for _, card := range cards {
+		if !sixtysix.IsTrump(card, v.Trump) && partnerOf(v.Hand, card) == sixtysix.NoCard {
+			spare = append(spare, card)
+		}
+	}
+	if len(spare) != 0 {
+		return lowest(spare)
+	}
+	return lowest(cards)
+}
+
+// partnerOf returns the king for a queen or the queen for a king from the same suit if it is in hand.
+func partnerOf(hand []deck.Card, card deck.Card) deck.Card {
+	if card.Rank() != deck.Queen && card.Rank() != deck.King {
+		return sixtysix.NoCard
+	}
+	p := partner(card)
+	for _, c := range hand {
+		if c == p {
+			return c
+		}
+	}
+	return sixtysix.NoCard
+}
+
+// bestMarriage returns the queen of the most valuable marriage in hand or NoCard.
+func bestMarriage(hand []deck.Card, trump deck.Card) deck.Card {
+	best := sixtysix.NoCard
+	for _, card := range hand {
+		if card == sixtysix.NoCard || card.Rank() != deck.Queen || partnerOf(hand, card) == sixtysix.NoCard {
+			continue
+		}
+		if best == sixtysix.NoCard || sixtysix.IsTrump(card, trump) {
+			best = card
+		}
+	}
+	return best
+}
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// remember learns what the opponent has or hasn't from a played card.
+// It must be called before the card is added to Played.
+func (v *View) remember(played protocol.PlayedBody) {
+	if played.Seat == v.Seat || v.Seat == sixtysix.Nobody {
+		return
+	}
+
+	for idx, card := range v.OpponentHas {
+		if card == played.Card {
+			v.OpponentHas = append(v.OpponentHas[:idx:idx], v.OpponentHas[idx+1:]...)
+			break
+		}
+	}
+	if played.Marriage != 0 {
+		v.OpponentHas = append(v.OpponentHas, partner(played.Card))
+	}
+
+	// the opponent has to follow suit and then to trump when the rules are strict
+	if len(v.Played)%2 == 0 || !v.IsStrict() {
+		return
+	}
+	led := v.Played[len(v.Played)-1]
+	if !deck.AreTheSameSuit(played.Card, led) {
+		v.OpponentVoid[led.Suit()] = true
+		if !sixtysix.IsTrump(played.Card, v.Trump) {
+			v.OpponentVoid[v.Trump.Suit()] = true
+		}
+	}
+}
+
+// partner returns the king for a queen and the queen for a king.
+func partner(card deck.Card) deck.Card {
+	if card.Rank() == deck.Queen {
+		return deck.NewCard(deck.King, card.Suit())
+	}
+	return deck.NewCard(deck.Queen, card.Suit())
+}
+
+// MayHave returns true if the opponent may have card in his hand now.
+func (v *View) MayHave(card deck.Card) bool {
+	for _, c := range v.OpponentHas {
+		if c == card {
+			return true
+		}
+	}
+	if v.OpponentVoid[card.Suit()] {
+		return false
+	}
+	for _, c := range v.Unseen() {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSafeToLead returns true if the opponent cannot win the trick if the player leads card.
+func (v *View) IsSafeToLead(card deck.Card) bool {
+	mayTrump := !v.IsStrict() || !v.hasSuit(card)
+	for _, c := range v.Unseen() {
+		if !v.MayHave(c) {
+			continue
+		}
+		if deck.AreTheSameSuit(c, card) && deck.HasHigherRank(c, card) {
+			return false
+		}
+		if mayTrump && !sixtysix.IsTrump(card, v.Trump) && sixtysix.IsTrump(c, v.Trump) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSuit returns true if the opponent is known to have a card from the suit of card.
+func (v *View) hasSuit(card deck.Card) bool {
+	for _, c := range v.OpponentHas {
+		if deck.AreTheSameSuit(c, card) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanStopAndWin returns true if the player wins the deal if he stops it now.
+// He needs enough points and more than his opponent has.
+func (v *View) CanStopAndWin() bool {
+	score, _ := v.DealScores()
+	return v.IsMyTurn() && v.Table == sixtysix.NoCard && v.Score >= sixtysix.WinningScore &&
+		v.Score > score[sixtysix.OpponentOf(v.Seat)]
+}
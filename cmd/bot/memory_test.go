@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+func TestRemember(t *testing.T) {
+	v := NewView()
+	v.Observe(message(protocol.Start, protocol.StartBody{Seat: sixtysix.Player1}))
+	v.Observe(message(protocol.State, protocol.StateBody{
+		Hand: cards("Q♥ 9♠ K♦ X♠ J♦ A♣"), Trump: card("A♥"), DeckSize: 8, Turn: sixtysix.Player2,
+	}))
+
+	v.Observe(message(protocol.Exchanged, protocol.SeatBody{Seat: sixtysix.Player2}))
+	v.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player2, Card: card("Q♣"), Marriage: 20}))
+	if len(v.OpponentHas) != 2 || !v.MayHave(card("A♥")) || !v.MayHave(card("K♣")) {
+		t.Error("Remember error!", v.OpponentHas)
+	}
+
+	v.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player1, Card: card("A♣")}))
+	v.Observe(message(protocol.TrickResult, protocol.ResultBody{Winner: sixtysix.Player1, Points: 14}))
+	v.Observe(message(protocol.Closed, protocol.SeatBody{Seat: sixtysix.Player1}))
+	v.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player1, Card: card("X♠")}))
+	v.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player2, Card: card("K♣")}))
+	if !v.OpponentVoid[deck.Spades] || !v.OpponentVoid[deck.Hearts] || v.MayHave(card("J♠")) || v.MayHave(card("K♣")) || len(v.OpponentHas) != 1 {
+		t.Error("Remember error!", v.OpponentVoid, v.OpponentHas)
+	}
+
+	v.Observe(message(protocol.DealResult, protocol.ResultBody{Winner: sixtysix.Player1, Points: 3}))
+	if len(v.OpponentHas) != 0 || len(v.OpponentVoid) != 0 {
+		t.Error("Expected to forget the last deal!")
+	}
+}
+
+func TestIsSafeToLead(t *testing.T) {
+	v := view(cards("A♠ X♠ K♦"), card("A♥"), sixtysix.NoCard, 0)
+	v.Closed = true
+	v.Played = cards("9♠ J♠ Q♠ K♠ 9♥ J♥")
+	if v.IsSafeToLead(card("A♠")) {
+		t.Error("The opponent may trump!")
+	}
+
+	v.OpponentVoid[deck.Hearts] = true
+	if !v.IsSafeToLead(card("A♠")) || !v.IsSafeToLead(card("X♠")) || v.IsSafeToLead(card("K♦")) {
+		t.Error("Safe to lead error!")
+	}
+}
+
+func TestCanStopAndWin(t *testing.T) {
+	v := view(cards("A♠"), card("A♥"), sixtysix.NoCard, 6)
+	v.Score = 66
+	v.Taken = [2]int{66, 70}
+	v.HasWonTrick = [2]bool{true, true}
+	if v.CanStopAndWin() {
+		t.Error("The opponent has more points!")
+	}
+	v.Taken[sixtysix.Player2] = 40
+	if !v.CanStopAndWin() {
+		t.Error("Expected to stop!")
+	}
+}
+
+func TestMonteCarloDealKnownCards(t *testing.T) {
+	s := &MonteCarlo{Rand: rand.New(rand.NewSource(1))}
+	v := view(cards("Q♥ 9♠ K♥ X♠ J♦ A♣"), card("A♥"), sixtysix.NoCard, 12)
+	v.OpponentHas = cards("K♣")
+	v.OpponentVoid[deck.Diamonds] = true
+	for i := 0; i < 20; i++ {
+		state, _ := s.deal(v)
+		hand := state.Hands[sixtysix.Player2]
+		if indexOf(hand, card("K♣")) == -1 {
+			t.Fatal("Expected a known card!", hand)
+		}
+		for _, c := range hand {
+			if c.Suit() == deck.Diamonds {
+				t.Fatal("Expected no diamonds!", hand)
+			}
+		}
+	}
+}
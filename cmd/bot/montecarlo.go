@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// Default budget of MonteCarlo.
+const (
+	DefaultIterations = 500
+	DefaultDuration   = time.Second
+)
+
+// MonteCarlo doesn't know the hand of the opponent while the deck is open,
+// so it deals the unseen cards at random many times. In each of these deals
+// it tries every card it can play and plays the rest of the deal: at random
+// while the deck is open and perfectly once the deck is empty or closed.
+// It plays the card which has won the most game points on average or closes
+// the deck if closing and then playing perfectly has won more. It always
+// exchanges the nine of trumps when it can. When the deck is empty it knows
+// both hands and plays perfectly.
+//
+// It stops after Iterations deals or after Duration, whichever comes first,
+// but it always tries at least one deal. Zero means no limit, but at least one
+// of the two must be set.
+type MonteCarlo struct {
+	Rand       *rand.Rand
+	Iterations int
+	Duration   time.Duration
+}
+
+// NewMonteCarlo returns a MonteCarlo with the default budget.
+func NewMonteCarlo(r *rand.Rand) *MonteCarlo {
+	return &MonteCarlo{Rand: r, Iterations: DefaultIterations, Duration: DefaultDuration}
+}
+
+// Act implements Strategy.
+func (s *MonteCarlo) Act(v *View) Action {
+	if v.CanStopAndWin() {
+		return Action{Type: Stop}
+	}
+	if e, ok := v.Endgame(); ok {
+		if solution := e.Solve(); solution.Stop {
+			return Action{Type: Stop}
+		} else if solution.Card != sixtysix.NoCard {
+			return Action{Type: Play, Card: solution.Card}
+		}
+	}
+
+	if v.CanExchange() {
+		return Action{Type: Exchange}
+	}
+
+	cards := v.LegalCards()
+	canClose := v.CanClose()
+	if len(cards) == 1 && !canClose {
+		return Action{Type: Play, Card: cards[0]}
+	}
+	if _, ok := s.deal(v); !ok {
+		return (&Advanced{}).Act(v)
+	}
+
+	totals := make([]int, len(cards))
+	closeTotal := 0
+	deadline := time.Now().Add(s.Duration)
+	for i := 0; i == 0 || s.hasBudget(i, deadline); i++ {
+		state, _ := s.deal(v)
+		for idx, card := range cards {
+			totals[idx] += s.simulate(state, v.Seat, card)
+		}
+		if canClose {
+			closeTotal += s.simulateClose(state, v.Seat)
+		}
+	}
+
+	best := 0
+	for idx := range cards {
+		if totals[idx] > totals[best] {
+			best = idx
+		}
+	}
+	if canClose && closeTotal > totals[best] {
+		return Action{Type: Close}
+	}
+	if v.Table == sixtysix.NoCard && sixtysix.MarriagePoints(v.Hand, cards[best], sixtysix.NoCard, v.Trump) != 0 {
+		return Action{Type: Marriage, Card: cards[best]}
+	}
+	return Action{Type: Play, Card: cards[best]}
+}
+
+// hasBudget returns true if another deal can be tried after i deals.
+func (s *MonteCarlo) hasBudget(i int, deadline time.Time) bool {
+	if s.Iterations != 0 && i >= s.Iterations {
+		return false
+	}
+	return s.Duration == 0 || time.Now().Before(deadline)
+}
+
+// deal returns a state of the game which agrees with everything the player has seen:
+// the unseen cards are dealt at random to the opponent and the deck, but the opponent
+// gets the cards he is known to have and none from the suits he is known not to have.
+// It returns false if the view is inconsistent.
+func (s *MonteCarlo) deal(v *View) (sixtysix.State, bool) {
+	opponent := sixtysix.OpponentOf(v.Seat)
+	unseen := v.Unseen()
+	size := v.OpponentHandSize()
+	talonSize := 0
+	if v.DeckSize != 0 {
+		talonSize = v.DeckSize - 1
+	}
+	if size < 0 || len(unseen) != size+talonSize {
+		return sixtysix.State{}, false
+	}
+
+	var known, possible, talon []deck.Card
+	for _, card := range unseen {
+		switch {
+		case indexOf(v.OpponentHas, card) != -1:
+			known = append(known, card)
+		case v.OpponentVoid[card.Suit()]:
+			talon = append(talon, card)
+		default:
+			possible = append(possible, card)
+		}
+	}
+	if len(known) > size || len(known)+len(possible) < size {
+		// the memory contradicts the counts, forget it
+		known, possible, talon = nil, unseen, nil
+	}
+	s.Rand.Shuffle(len(possible), func(i, j int) { possible[i], possible[j] = possible[j], possible[i] })
+	hand := append(known, possible[:size-len(known)]...)
+	talon = append(talon, possible[size-len(known):]...)
+	s.Rand.Shuffle(len(talon), func(i, j int) { talon[i], talon[j] = talon[j], talon[i] })
+
+	state := sixtysix.State{
+		Talon:        talon,
+		GameScore:    v.GameScore,
+		Trump:        v.Trump,
+		ClosedBy:     v.ClosedBy,
+		HasTrickWon:  v.HasWonTrick,
+		PlayerInTurn: v.Seat,
+	}
+	state.DealScore, state.Marriages = v.DealScores()
+	state.Hands[v.Seat] = v.MyCards()
+	state.Hands[opponent] = hand
+	if v.Table != sixtysix.NoCard {
+		state.Hands[opponent] = append(state.Hands[opponent], sixtysix.NoCard)
+		state.EmptyCardSlots[opponent] = size
+		state.Trick[opponent] = v.Table
+	}
+	return state, true
+}
+
+// simulate plays card from the hand of player and the rest of the deal.
+// It returns the game points player has won or minus those he has lost.
+func (s *MonteCarlo) simulate(state sixtysix.State, player int, card deck.Card) int {
+	g := sixtysix.Restore(state)
+	move, _ := g.Play(player, indexOf(g.Hand(player), card))
+	for move.DealWinner == sixtysix.Nobody {
+		inTurn := g.PlayerInTurn()
+		if solution, err := g.Solve(); err == nil {
+			return value(player, solution.Winner, solution.Points)
+		}
+		if g.CanStop(inTurn) && g.DealScore(inTurn) >= sixtysix.WinningScore {
+			_, winner, pts := g.Stop(inTurn)
+			return value(player, winner, pts)
+		}
+
+		idxs := g.LegalCards(inTurn)
+		move, _ = g.Play(inTurn, idxs[s.Rand.Intn(len(idxs))])
+	}
+	return value(player, move.DealWinner, move.DealPoints)
+}
+
+// simulateClose closes the deck and plays the rest of the deal perfectly.
+// It returns the game points player has won or minus those he has lost.
+func (s *MonteCarlo) simulateClose(state sixtysix.State, player int) int {
+	g := sixtysix.Restore(state)
+	g.Close(player)
+	solution, _ := g.Solve()
+	return value(player, solution.Winner, solution.Points)
+}
+
+// value returns pts for player if he is the winner and -pts otherwise.
+func value(player, winner, pts int) int {
+	if winner == player {
+		return pts
+	}
+	return -pts
+}
+
+// indexOf returns the index of card in hand or -1.
+func indexOf(hand []deck.Card, card deck.Card) int {
+	for idx, c := range hand {
+		if c == card {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+func TestMonteCarloDeal(t *testing.T) {
+	s := &MonteCarlo{Rand: rand.New(rand.NewSource(1)), Iterations: 10}
+	v := view(cards("Q♥ 9♠ K♥ X♠ J♦ A♣"), card("A♥"), card("K♣"), 12)
+	v.Played = cards("K♣")
+
+	state, ok := s.deal(v)
+	if !ok || len(state.Hands[sixtysix.Player2]) != 6 || len(state.Talon) != 11 {
+		t.Fatal("Deal error!", state)
+	}
+	if state.Hands[sixtysix.Player2][state.EmptyCardSlots[sixtysix.Player2]] != sixtysix.NoCard {
+		t.Error("Expected a hole in the hand of the opponent!")
+	}
+
+	seen := map[deck.Card]bool{v.Trump: true, v.Table: true}
+	for _, card := range append(append(state.Hands[sixtysix.Player1], state.Hands[sixtysix.Player2]...), state.Talon...) {
+		if card != sixtysix.NoCard && seen[card] {
+			t.Error("Card dealt twice!", card)
+		}
+		seen[card] = true
+	}
+
+	v.Played = nil
+	if _, ok := s.deal(v); ok {
+		t.Error("Expected inconsistent view!")
+	}
+}
+
+func TestMonteCarlo(t *testing.T) {
+	s := &MonteCarlo{Rand: rand.New(rand.NewSource(1)), Iterations: 30}
+	v := view(cards("Q♥ 9♠ K♥ X♠ J♦ A♣"), card("A♥"), card("K♣"), 12)
+	v.Played = cards("K♣")
+	if action := s.Act(v); action.Type != Play || !contains(v.LegalCards(), action.Card) {
+		t.Error("Illegal card!", action)
+	}
+
+	s = &MonteCarlo{Rand: rand.New(rand.NewSource(1)), Duration: 50 * time.Millisecond}
+	v.Closed, v.ClosedBy = true, sixtysix.Player2
+	start := time.Now()
+	if action := s.Act(v); action.Type != Play || !contains(v.LegalCards(), action.Card) {
+		t.Error("Illegal card!", action)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("MonteCarlo exceeded its budget!")
+	}
+}
+
+// contains returns true if card is in cards.
+func contains(cards []deck.Card, card deck.Card) bool {
+	return indexOf(cards, card) != -1
+}
+
+func TestMonteCarloExchange(t *testing.T) {
+	s := &MonteCarlo{Rand: rand.New(rand.NewSource(1)), Iterations: 10}
+	v := view(cards("9♥ 9♠ K♦ X♠ J♣ Q♣"), card("A♥"), sixtysix.NoCard, 8)
+	v.HasWonTrick[v.Seat] = true
+	if action := s.Act(v); action.Type != Exchange {
+		t.Error("Expected exchange!", action)
+	}
+}
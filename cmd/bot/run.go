@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"github.com/DanislavKirov/sixtySix/cmd/fair"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+)
+
+// send sends action to the server.
+func send(conn *protocol.Conn, action Action) error {
+	switch action.Type {
+	case Close:
+		return conn.Send(protocol.Close, nil)
+	case Exchange:
+		return conn.Send(protocol.Exchange, nil)
+	case Stop:
+		return conn.Send(protocol.Stop, nil)
+	}
+	return conn.Send(protocol.Play, protocol.PlayBody{Card: action.Card})
+}
+
+// MaxMistakes is how many actions in a row a strategy may get wrong
+// before a legal card is played for it.
+const MaxMistakes = 3
+
+// Run plays with strategy s on the connection conn, which must have finished
+// the handshake, until the game is over. It returns nil if the game has ended
+// normally. The bot takes part in the deals and leaves with *fair.Unfair
+// if the server has cheated. The server is answered while the bot thinks,
+// so it doesn't take a slow strategy for a lost connection.
+func Run(conn *protocol.Conn, s Strategy) error {
+	deals, err := fair.NewPlayer()
+	if err != nil {
+		return err
+	}
+	messages := make(chan protocol.Received)
+	done := make(chan struct{})
+	defer close(done)
+	go conn.Listen(messages, done)
+
+	view := NewView()
+	mistakes := 0
+	for {
+		received := <-messages
+		message, err := received.Message, received.Err
+		if err != nil {
+			return err
+		}
+		if err := deals.Handle(conn, message); err != nil {
+			return err
+		}
+		if err := view.Observe(message); err != nil {
+			return err
+		}
+		if o, ok := s.(Observer); ok {
+			if err := o.Observe(message); err != nil {
+				return err
+			}
+		}
+
+		switch message.Type {
+		case protocol.State:
+			mistakes = 0
+			if view.IsMyTurn() {
+				err = send(conn, s.Act(view))
+			}
+		case protocol.Error:
+			// the strategy has made a mistake, let it try again and then play anything
+			mistakes++
+			if cards := view.LegalCards(); view.IsMyTurn() && mistakes < MaxMistakes {
+				err = send(conn, s.Act(view))
+			} else if view.IsMyTurn() && len(cards) != 0 {
+				err = send(conn, Action{Type: Play, Card: cards[0]})
+			}
+		case protocol.GameResult, protocol.OpponentLeft:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
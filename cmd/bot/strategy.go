@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// ActionType is what a player can do when it's his turn.
+type ActionType int
+
+// Action types.
+const (
+	Play     ActionType = iota // play Card
+	Marriage                   // play Card, a queen or a king, and announce the marriage
+	Close
+	Exchange
+	Stop
+)
+
+// Action is what a strategy has decided to do.
+type Action struct {
+	Type ActionType
+	Card deck.Card
+}
+
+// Strategy decides what the player does when it's his turn.
+// Act is called only when v.IsMyTurn() is true.
+type Strategy interface {
+	Act(v *View) Action
+}
+
+// Observer is a strategy which wants to see every message the server sends,
+// for example to pass it on to another program. Observe is called after the
+// view has been updated and before Act.
+type Observer interface {
+	Observe(message protocol.Message) error
+}
+
+// Names are the names of the built-in strategies from the easiest to the hardest.
+var Names = []string{"random", "greedy", "advanced", "expert"}
+
+// New returns the built-in strategy with the given name which uses r for its random choices.
+func New(name string, r *rand.Rand) (Strategy, error) {
+	switch name {
+	case "random":
+		return &Random{r}, nil
+	case "greedy":
+		return &Greedy{r}, nil
+	case "advanced":
+		return &Advanced{}, nil
+	case "expert":
+		return NewMonteCarlo(r), nil
+	}
+	return nil, errors.New("Unknown strategy: " + name)
+}
+
+// Random plays a random card it is allowed to play and stops when it has enough points.
+type Random struct {
+	Rand *rand.Rand
+}
+
+// Act implements Strategy.
+func (s *Random) Act(v *View) Action {
+	if v.Table == sixtysix.NoCard && v.Score >= sixtysix.WinningScore {
+		return Action{Type: Stop}
+	}
+
+	cards := v.LegalCards()
+	return Action{Type: Play, Card: cards[s.Rand.Intn(len(cards))]}
+}
+
+// Greedy wins the trick if it can, otherwise it plays its lowest card.
+// It leads a random card and stops when it has enough points.
+type Greedy struct {
+	Rand *rand.Rand
+}
+
+// Act implements Strategy.
+func (s *Greedy) Act(v *View) Action {
+	if v.Table == sixtysix.NoCard {
+		if v.Score >= sixtysix.WinningScore {
+			return Action{Type: Stop}
+		}
+		cards := v.LegalCards()
+		return Action{Type: Play, Card: cards[s.Rand.Intn(len(cards))]}
+	}
+
+	cards := v.LegalCards()
+	if idx := pickCard(cards, v.Table, v.Trump); idx != 0 {
+		return Action{Type: Play, Card: cards[idx-1]}
+	}
+	return Action{Type: Play, Card: cards[findLowestRank(cards)-1]}
+}
+
+// pickCard returns index of card from hand which can win the trick against card.
+// It returns 0 if bot can't win with any card.
+func pickCard(hand []deck.Card, card, trump deck.Card) int {
+	for idx, c := range hand {
+		if sixtysix.Beats(c, card, trump) {
+			return idx + 1
+		}
+	}
+	return 0
+}
+
+// findLowestRank returns the index of the lowest rank card.
+func findLowestRank(hand []deck.Card) int {
+	idx, rank := 0, deck.Ace
+	for i, card := range hand {
+		if card.Rank() < rank {
+			rank = card.Rank()
+			idx = i
+		}
+	}
+
+	return idx + 1
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+func TestPickCard(t *testing.T) {
+	hand := cards("J♥ Q♦ A♥ A♦")
+	if pickCard(hand, card("Q♥"), card("K♥")) != 3 {
+		t.Error("Pick card error!")
+	}
+
+	hand = cards("J♥ Q♠ 9♥ A♠")
+	if idx := pickCard(hand, card("Q♥"), card("K♦")); idx != 0 {
+		t.Error("Pick card error!", idx)
+	}
+}
+
+func TestFindLowestRank(t *testing.T) {
+	hand := cards("J♥ Q♠ 9♥ A♦")
+	if findLowestRank(hand) != 3 {
+		t.Error("Lowest rank error!")
+	}
+	if findLowestRank(cards("A♦")) != 1 {
+		t.Error("Lowest rank error!")
+	}
+}
+
+// card and cards return the cards written in the tests.
+var card = deck.MustParse
+
+func cards(s string) []deck.Card {
+	c, err := deck.ParseCards(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// view returns a view of a player in turn for the tests.
+func view(hand []deck.Card, trump, table deck.Card, deckSize int) *View {
+	v := NewView()
+	v.Seat, v.Turn = 0, 0
+	v.Hand, v.Trump, v.Table, v.DeckSize = hand, trump, table, deckSize
+	return v
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Names {
+		if s, err := New(name, rand.New(rand.NewSource(1))); err != nil || s == nil {
+			t.Error("Strategy error!", name)
+		}
+	}
+	if _, err := New("unknown", nil); err == nil {
+		t.Error("Expected unknown strategy!")
+	}
+}
+
+func TestStrategiesPlayLegalCards(t *testing.T) {
+	v := view(cards("Q♥ 9♥ K♠ X♠"), card("A♥"), card("J♠"), 0)
+	for _, name := range Names {
+		s, _ := New(name, rand.New(rand.NewSource(1)))
+		for i := 0; i < 10; i++ {
+			if action := s.Act(v); action.Type != Play || (action.Card != card("K♠") && action.Card != card("X♠")) {
+				t.Error("Illegal card!", name, action)
+			}
+		}
+	}
+}
+
+func TestAdvanced(t *testing.T) {
+	s := &Advanced{}
+	if action := s.Act(view(cards("Q♥ 9♠ K♥ X♠"), card("A♥"), deck.NoCard, 8)); action.Type != Marriage || action.Card != card("Q♥") {
+		t.Error("Expected marriage!", action)
+	}
+	if action := s.Act(view(cards("J♥ 9♠ K♦ X♠"), card("A♥"), deck.NoCard, 8)); action.Card != card("9♠") {
+		t.Error("Expected the lowest card!", action)
+	}
+	if action := s.Act(view(cards("J♥ 9♠ K♦ X♠"), card("A♥"), card("A♦"), 8)); action.Card != card("J♥") {
+		t.Error("Expected a trump!", action)
+	}
+	if action := s.Act(view(cards("J♥ 9♠ K♦ X♠"), card("A♥"), card("J♠"), 8)); action.Card != card("X♠") {
+		t.Error("Expected the ten!", action)
+	}
+
+	v := view(cards("J♥ 9♠"), card("A♥"), deck.NoCard, 8)
+	v.Score = 70
+	if action := s.Act(v); action.Type != Stop {
+		t.Error("Expected stop!", action)
+	}
+}
+
+func TestAdvancedExchangeAndClose(t *testing.T) {
+	s := &Advanced{}
+	v := view(cards("9♥ 9♠ K♦ X♠ J♣ Q♣"), card("A♥"), deck.NoCard, 8)
+	if action := s.Act(v); action.Type != Play && action.Type != Marriage {
+		t.Error("Expected no exchange before winning a trick!", action)
+	}
+	v.HasWonTrick[v.Seat] = true
+	if action := s.Act(v); action.Type != Exchange {
+		t.Error("Expected exchange!", action)
+	}
+
+	v = view(cards("A♥ X♥ K♥ Q♥ A♠ X♠"), card("J♥"), deck.NoCard, 8)
+	v.HasWonTrick[v.Seat] = true
+	v.Score = 10
+	if action := s.Act(v); action.Type != Close {
+		t.Error("Expected close!", action)
+	}
+	v.Hand = cards("9♥ X♣ K♥ Q♥ A♠ X♠")
+	if action := s.Act(v); action.Type == Close {
+		t.Error("Expected not to close!", action)
+	}
+}
@@ -0,0 +1,240 @@
+// Package bot provides what a computer player needs to take part in a game
+// of Sixty-six: its own view of the game, built only from the messages the
+// server sends to it.
+package bot
+
+import (
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// View is what a player knows about the game.
+type View struct {
+	Seat      int
+	Hand      []deck.Card
+	Trump     deck.Card
+	DeckSize  int // counting the trump
+	Closed    bool
+	ClosedBy  int
+	Table     deck.Card // the card of the opponent in the current trick
+	Score     int
+	GameScore [2]int
+	Turn      int
+
+	Played      []deck.Card // the cards played in the current deal
+	HasWonTrick [2]bool
+	Taken       [2]int // the points from the tricks won by each player
+	Announced   [2]int // the points from the marriages announced by each player
+
+	OpponentHas  []deck.Card        // the cards the opponent is known to have
+	OpponentVoid map[deck.Suit]bool // the suits the opponent is known not to have
+}
+
+// NewView returns the view of a player who hasn't been seated yet.
+func NewView() *View {
+	return &View{
+		Seat:         sixtysix.Nobody,
+		ClosedBy:     sixtysix.Nobody,
+		Turn:         sixtysix.Nobody,
+		OpponentVoid: make(map[deck.Suit]bool),
+	}
+}
+
+// newDeal forgets what happened in the last deal.
+func (v *View) newDeal() {
+	v.Played = nil
+	v.HasWonTrick = [2]bool{}
+	v.Taken = [2]int{}
+	v.Announced = [2]int{}
+	v.OpponentHas = nil
+	v.OpponentVoid = make(map[deck.Suit]bool)
+	v.ClosedBy = sixtysix.Nobody
+}
+
+// Observe updates the view with what the server sent.
+func (v *View) Observe(message protocol.Message) error {
+	switch message.Type {
+	case protocol.Start:
+		var start protocol.StartBody
+		if err := message.Decode(&start); err != nil {
+			return err
+		}
+		v.Seat = start.Seat
+		v.GameScore = [2]int{}
+		v.newDeal()
+	case protocol.State:
+		var state protocol.StateBody
+		if err := message.Decode(&state); err != nil {
+			return err
+		}
+		v.Hand = state.Hand
+		v.Trump = state.Trump
+		v.DeckSize = state.DeckSize
+		v.Closed = state.Closed
+		v.Table = state.Table
+		v.Score = state.Score
+		v.GameScore = state.GameScore
+		v.Turn = state.Turn
+	case protocol.Played:
+		var played protocol.PlayedBody
+		if err := message.Decode(&played); err != nil {
+			return err
+		}
+		v.remember(played)
+		v.Played = append(v.Played, played.Card)
+		v.Announced[played.Seat] += played.Marriage
+	case protocol.Closed:
+		var closed protocol.SeatBody
+		if err := message.Decode(&closed); err != nil {
+			return err
+		}
+		v.ClosedBy = closed.Seat
+		v.Closed = true
+	case protocol.Exchanged:
+		var exchanged protocol.SeatBody
+		if err := message.Decode(&exchanged); err != nil {
+			return err
+		}
+		if exchanged.Seat != v.Seat && v.Trump != sixtysix.NoCard {
+			v.OpponentHas = append(v.OpponentHas, v.Trump) // the new trump comes with the next state
+		}
+	case protocol.TrickResult:
+		var result protocol.ResultBody
+		if err := message.Decode(&result); err != nil {
+			return err
+		}
+		v.HasWonTrick[result.Winner] = true
+		v.Taken[result.Winner] += result.Points
+	case protocol.DealResult:
+		v.newDeal()
+	}
+	return nil
+}
+
+// IsMyTurn returns true if the player has to act now.
+func (v *View) IsMyTurn() bool {
+	return v.Seat != sixtysix.Nobody && v.Turn == v.Seat
+}
+
+// IsStrict returns true if the player has to follow suit.
+func (v *View) IsStrict() bool {
+	return v.Closed || v.DeckSize == 0
+}
+
+// CanExchange returns true if the player can exchange the nine of trumps for the trump now.
+func (v *View) CanExchange() bool {
+	if !v.IsMyTurn() || v.Table != sixtysix.NoCard || v.Closed || v.DeckSize < 2 ||
+		!v.HasWonTrick[v.Seat] || v.Trump.Rank() == deck.Nine {
+		return false
+	}
+	return v.nineOfTrumps() != sixtysix.NoCard
+}
+
+// nineOfTrumps returns the nine of trumps if it is in the hand or NoCard.
+func (v *View) nineOfTrumps() deck.Card {
+	for _, card := range v.Hand {
+		if card != sixtysix.NoCard && card.Rank() == deck.Nine && sixtysix.IsTrump(card, v.Trump) {
+			return card
+		}
+	}
+	return sixtysix.NoCard
+}
+
+// CanClose returns true if the player can close the deck now.
+func (v *View) CanClose() bool {
+	return v.IsMyTurn() && v.Table == sixtysix.NoCard && !v.Closed && v.DeckSize >= 2
+}
+
+// LegalCards returns the cards from the hand which can be played now.
+func (v *View) LegalCards() []deck.Card {
+	var cards []deck.Card
+	for _, card := range v.Hand {
+		if card == sixtysix.NoCard {
+			continue
+		}
+		if v.Table == sixtysix.NoCard || sixtysix.IsGoodResponse(v.Hand, card, v.Table, v.Trump, v.IsStrict()) {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// Endgame returns the position if the deck is empty and it's the player's turn. Then the hand of the
+// opponent is known: it has all the cards which are neither in the hand of
+// the player nor played. It returns false if the position isn't known.
+func (v *View) Endgame() (sixtysix.Endgame, bool) {
+	if v.DeckSize != 0 || !v.IsMyTurn() {
+		return sixtysix.Endgame{}, false
+	}
+
+	mine, opponents := v.MyCards(), v.Unseen()
+	opponent := sixtysix.OpponentOf(v.Seat)
+	if len(opponents) != v.OpponentHandSize() {
+		return sixtysix.Endgame{}, false
+	}
+
+	e := sixtysix.Endgame{
+		Trump:       v.Trump,
+		Table:       v.Table,
+		Turn:        v.Turn,
+		ClosedBy:    v.ClosedBy,
+		HasTrickWon: v.HasWonTrick,
+	}
+	e.Hands[v.Seat], e.Hands[opponent] = mine, opponents
+	e.Score, e.Marriages = v.DealScores()
+	return e, true
+}
+
+// DealScores returns the deal points of both players and the points from
+// the marriages which are not counted yet because the player hasn't won a trick.
+func (v *View) DealScores() (score, marriages [2]int) {
+	for player := range score {
+		score[player] = v.Taken[player]
+		if v.HasWonTrick[player] {
+			score[player] += v.Announced[player]
+		} else {
+			marriages[player] = v.Announced[player]
+		}
+	}
+	return score, marriages
+}
+
+// MyCards returns the cards in the hand without the holes.
+func (v *View) MyCards() []deck.Card {
+	var cards []deck.Card
+	for _, card := range v.Hand {
+		if card != sixtysix.NoCard {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// Unseen returns the cards which are either in the hand of the opponent or in the deck
+// under the trump: those which are neither in the hand, nor played, nor the trump.
+func (v *View) Unseen() []deck.Card {
+	seen := make(map[deck.Card]bool)
+	for _, card := range append(v.Hand, v.Played...) {
+		seen[card] = true
+	}
+	if v.DeckSize != 0 {
+		seen[v.Trump] = true
+	}
+
+	var cards []deck.Card
+	for _, card := range deck.OrderedDeck {
+		if !seen[card] {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// OpponentHandSize returns how many cards the opponent has when it's the player's turn.
+func (v *View) OpponentHandSize() int {
+	if v.Table != sixtysix.NoCard {
+		return len(v.MyCards()) - 1
+	}
+	return len(v.MyCards())
+}
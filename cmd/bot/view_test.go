@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// message returns a message with type t and body for the tests.
+func message(t protocol.Type, body interface{}) protocol.Message {
+	m, _ := protocol.NewMessage(t, body)
+	return m
+}
+
+func TestObserve(t *testing.T) {
+	v := NewView()
+	v.Observe(message(protocol.Start, protocol.StartBody{Seat: sixtysix.Player2}))
+	v.Observe(message(protocol.State, protocol.StateBody{
+		Hand: cards("Q♥ 9♥ K♥ X♠ J♦ A♣"), Trump: card("A♥"), DeckSize: 12, Turn: sixtysix.Player1,
+	}))
+	if v.Seat != sixtysix.Player2 || v.IsMyTurn() || len(v.Hand) != 6 || v.Trump != card("A♥") {
+		t.Error("Observe error!")
+	}
+
+	v.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player1, Card: card("K♣")}))
+	v.Observe(message(protocol.State, protocol.StateBody{
+		Hand: cards("Q♥ 9♥ K♥ X♠ J♦ A♣"), Trump: card("A♥"), DeckSize: 12, Table: card("K♣"), Turn: sixtysix.Player2,
+	}))
+	v.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player2, Card: card("A♣")}))
+	v.Observe(message(protocol.TrickResult, protocol.ResultBody{Winner: sixtysix.Player2, Points: 15}))
+	if !v.HasWonTrick[sixtysix.Player2] || v.HasWonTrick[sixtysix.Player1] || len(v.Played) != 2 {
+		t.Error("Observe error!")
+	}
+
+	v.Observe(message(protocol.DealResult, protocol.ResultBody{Winner: sixtysix.Player2, Points: 1}))
+	if v.HasWonTrick[sixtysix.Player2] || len(v.Played) != 0 {
+		t.Error("New deal error!")
+	}
+
+	if err := v.Observe(protocol.Message{Type: protocol.State, Body: "{"}); err == nil {
+		t.Error("Expected decode error!")
+	}
+}
+
+func TestLegalCards(t *testing.T) {
+	v := NewView()
+	v.Hand = cards("Q♥ 9♥ K♠ X♠")
+	v.Trump = card("A♥")
+	v.Table = card("J♠")
+	v.DeckSize = 8
+	if len(v.LegalCards()) != 4 {
+		t.Error("Legal cards error!")
+	}
+
+	v.Closed = true
+	if cards := v.LegalCards(); len(cards) != 2 || cards[0] != card("K♠") || cards[1] != card("X♠") {
+		t.Error("Legal cards error!", cards)
+	}
+}
+
+func TestEndgame(t *testing.T) {
+	v := NewView()
+	v.Seat, v.Turn = sixtysix.Player1, sixtysix.Player1
+	v.Hand = cards("A♠ - X♠")
+	v.Trump = card("Q♥")
+	v.DeckSize = 8
+	for _, c := range deck.OrderedDeck {
+		if c != card("A♠") && c != card("X♠") && c != card("9♠") && c != card("J♠") {
+			v.Played = append(v.Played, c)
+		}
+	}
+	if _, ok := v.Endgame(); ok {
+		t.Error("Expected unknown position!")
+	}
+
+	v.DeckSize = 0
+	v.Taken = [2]int{50, 40}
+	v.Announced = [2]int{0, 20}
+	v.HasWonTrick = [2]bool{true, false}
+	e, ok := v.Endgame()
+	if !ok || len(e.Hands[sixtysix.Player1]) != 2 || len(e.Hands[sixtysix.Player2]) != 2 {
+		t.Fatal("Endgame error!")
+	}
+	if e.Score != [2]int{50, 40} || e.Marriages != [2]int{0, 20} {
+		t.Error("Endgame score error!", e.Score, e.Marriages)
+	}
+	if a := (&Advanced{}).Act(v); a.Type != Play || a.Card != card("A♠") {
+		t.Error("Advanced endgame error!", a)
+	}
+}
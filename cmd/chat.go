@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// chatBurst is how many messages a player can say at once and chatInterval
+// how long he has to wait for each one after that.
+const (
+	chatBurst    = 3
+	chatInterval = 2 * time.Second
+)
+
+// limiter counts how many messages a player can say now.
+type limiter struct {
+	allowance time.Duration // chatInterval for each message
+	last      time.Time
+}
+
+// allow returns true and counts the message if the player can say one now.
+func (l *limiter) allow(now time.Time) bool {
+	if l.last.IsZero() {
+		l.allowance = chatBurst * chatInterval
+	} else {
+		l.allowance += now.Sub(l.last)
+	}
+	if l.allowance > chatBurst*chatInterval {
+		l.allowance = chatBurst * chatInterval
+	}
+	l.last = now
+
+	if l.allowance < chatInterval {
+		return false
+	}
+	l.allowance -= chatInterval
+	return true
+}
+
+// isEmote returns true if name is one of protocol.Emotes.
+func isEmote(name string) bool {
+	for _, emote := range protocol.Emotes {
+		if name == emote {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanText returns text without the characters which can change the terminal
+// of the others and the spaces around it.
+func cleanText(text string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text))
+}
+
+// chat relays what player says in message to his opponent and the spectators or
+// remembers whether he has muted his opponent. It returns false if message isn't about chat.
+func (m *match) chat(player int, message protocol.Message) bool {
+	switch message.Type {
+	case protocol.Say:
+		var say protocol.SayBody
+		if err := message.Decode(&say); err != nil {
+			m.sendError(player, protocol.BadMessage, err.Error())
+			return true
+		}
+		said := protocol.SaidBody{Seat: player, Text: cleanText(say.Text), Emote: say.Emote}
+		if said.Emote != "" && (said.Text != "" || !isEmote(said.Emote)) ||
+			said.Emote == "" && (said.Text == "" || utf8.RuneCountInString(said.Text) > protocol.MaxSay) {
+			m.sendError(player, protocol.BadMessage, "Say up to "+strconv.Itoa(protocol.MaxSay)+" characters or one of: "+strings.Join(protocol.Emotes, ", "))
+			return true
+		}
+		if !m.limits[player].allow(time.Now()) {
+			m.sendError(player, protocol.RateLimited, "Too many messages, wait a little")
+			return true
+		}
+
+		if opponent := sixtysix.OpponentOf(player); !m.muted[opponent] {
+			m.sendTo(opponent, protocol.Said, said)
+		}
+		m.show(protocol.Said, said)
+	case protocol.Mute:
+		var mute protocol.MuteBody
+		if err := message.Decode(&mute); err != nil {
+			m.sendError(player, protocol.BadMessage, err.Error())
+			return true
+		}
+		m.muted[player] = mute.Muted
+	default:
+		return false
+	}
+	return true
+}
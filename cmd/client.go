@@ -2,34 +2,133 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+	"github.com/DanislavKirov/sixtySix/cmd/clock"
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/discovery"
+	"github.com/DanislavKirov/sixtySix/cmd/fair"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
 )
 
+// stdin is where everything the player writes is read from.
+var stdin = bufio.NewReader(os.Stdin)
+
+// notation is how the cards are shown to the player and written by him, set by a flag.
+var notation = deck.Unicode
+
+// pick asks the player to pick one of the options and returns its number (1, 2, ...).
+// It returns 0 if there is nothing more to read.
+func pick(question string, options []string) int {
+	for {
+		fmt.Print(question)
+		for idx, option := range options {
+			fmt.Print("\n" + strconv.Itoa(idx+1) + ". " + option)
+		}
+		fmt.Print("\nYour choice: ")
+
+		input, err := stdin.ReadString('\n')
+		if err == io.EOF {
+			return 0
+		}
+		choice, err := strconv.Atoi(strings.TrimSpace(input))
+		if err == nil && choice >= 1 && choice <= len(options) {
+			return choice
+		}
+	}
+}
+
 // menu connects the client depending on his choice.
 func menu() {
-	choice := 0
-	reader := bufio.NewReader(os.Stdin)
-	for choice < 1 || choice > 3 {
-		fmt.Print("\nPick one:\n1. Create game\n2. Join game\n3. Single player\nYour choice: ")
-		input, err := reader.ReadString('\n')
-		if err != nil || len(input) > 2 {
-			continue
-		}
-		choice = int(input[0] - '0')
+	options := []string{"Create game", "Join game", "Single player", "Watch game", "Lobby"}
+	saved, err := loadResume()
+	if err == nil {
+		options = append(options, "Resume game on "+saved.Address)
 	}
 
-	switch choice {
+	switch pick("\nPick one:", options) {
 	case 1:
 		client1()
 	case 2:
 		client2()
 	case 3:
-		client3()
+		if difficulty := pick("\nPick difficulty:", bot.Names); difficulty != 0 {
+			client3(bot.Names[difficulty-1])
+		}
+	case 4:
+		clientWatch()
+	case 5:
+		clientLobby()
+	case 6:
+		connect(saved.Address, protocol.HelloBody{Token: saved.Token}, nil)
+	}
+}
+
+// resumeInfo is what the client keeps to come back to a saved match.
+type resumeInfo struct {
+	Address string `json:"address"`
+	Token   string `json:"token"`
+}
+
+// resumePath returns the file where the client keeps the saved match.
+func resumePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sixtySix", "resume.json"), nil
+}
+
+// loadResume returns the saved match the player can come back to.
+func loadResume() (resumeInfo, error) {
+	var info resumeInfo
+	path, err := resumePath()
+	if err != nil {
+		return info, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil || info.Token == "" {
+		return info, errors.New("No saved game")
+	}
+	return info, nil
+}
+
+// saveResume keeps info, so that the player can come back to the match later.
+func saveResume(info resumeInfo) error {
+	path, err := resumePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// forgetResume removes the saved match after it is over.
+func forgetResume() {
+	if path, err := resumePath(); err == nil {
+		os.Remove(path)
 	}
 }
 
@@ -71,119 +170,936 @@ func findIP() (string, error) {
 	return "", errors.New("No network connection.")
 }
 
-// client1 starts the server and connects the first player.
+// client1 starts the server, announces it on the local network and connects the first player.
 func client1() {
-	wg.Add(1)
-	go startServer()
-	ip, err := findIP()
+	s, err := startServer(":0")
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	wg.Wait()
-	port := server.Addr().String()
-	idx := strings.LastIndex(port, ":")
-	fmt.Println("IP:port = " + ip + port[idx:])
-	connect("localhost"+port[idx:], false)
+	defer s.close()
+	go s.serve()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go announce(s.port(), stop)
+
+	// the other player can also write the address if the game isn't found
+	if ip, err := findIP(); err == nil {
+		fmt.Println("IP:port = " + net.JoinHostPort(ip, s.port()))
+	} else {
+		fmt.Println(err)
+	}
+	connect(net.JoinHostPort("localhost", s.port()), protocol.HelloBody{}, nil)
+}
+
+// announce tells the local network about the game on port until stop is closed.
+func announce(port string, stop <-chan struct{}) {
+	name, err := os.Hostname()
+	if err != nil {
+		name = "sixtySix"
+	}
+	number, err := strconv.Atoi(port)
+	if err == nil {
+		err = discovery.Announce(discovery.Addresses(discovery.Port), name, number, stop)
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
 }
 
-// client2 connects the second player to the server entering IP:port.
+// browseTime is how long the client looks for games on the local network.
+const browseTime = 2 * discovery.Interval
+
+// client2 connects the second player to a server found on the local network or at the IP:port he enters.
 func client2() {
+	fmt.Print(Searching)
+	games, err := discovery.Browse(browseTime)
+	if err != nil {
+		fmt.Println(err)
+	}
+	var options []string
+	for _, game := range games {
+		options = append(options, game.Name+" ("+game.Address+")")
+	}
+	choice := pick("\nPick a game:", append(options, "Enter ip:port"))
+	if choice == 0 {
+		return
+	}
+	if choice <= len(games) {
+		connect(games[choice-1].Address, protocol.HelloBody{}, nil)
+		return
+	}
+
 	fmt.Print("Enter ip:port: ")
-	reader := bufio.NewReader(os.Stdin)
-	ip, err := reader.ReadString('\n')
-	for err != nil {
-		fmt.Println(TryAgain)
-		ip, err = reader.ReadString('\n')
+	ip, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
-	connect(ip[:len(ip)-1], false)
+	connect(strings.TrimSpace(ip), protocol.HelloBody{}, nil)
 }
 
-// client3 starts the server, connects the player and creates a bot.
-func client3() {
-	wg.Add(1)
-	go startServer()
-	wg.Wait()
-	port := server.Addr().String()
-	idx := strings.LastIndex(port, ":")
-	ip := "localhost" + port[idx:]
-	wg.Add(1)
-	go connect(ip, true)
-	wg.Wait()
-	startBot(ip)
+// clientLobby asks the player for the server and his name and takes him to its lobby.
+func clientLobby() {
+	fmt.Print("Enter ip:port: ")
+	ip, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print("Enter your name, so others can invite you: ")
+	name, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	connect(strings.TrimSpace(ip), protocol.HelloBody{Name: strings.TrimSpace(name), Lobby: true}, nil)
 }
 
-// connect creates a client-server connection and communicates through it.
-func connect(ip string, singlePlayer bool) {
-	connection, err := net.Dial("tcp", ip)
+// clientWatch asks the player which match he wants to watch and how and connects him to it.
+func clientWatch() {
+	fmt.Print("Enter ip:port: ")
+	ip, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print("Enter the number of the match: ")
+	id, err := stdin.ReadString('\n')
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
+	mode := pick("\nHow do you want to watch?", []string{"Live", "As a commentator, seeing the hands after each deal"})
+	if mode == 0 {
+		return
+	}
+	password := ""
+	if mode == 2 {
+		fmt.Print("Enter the password of the commentators: ")
+		if password, err = stdin.ReadString('\n'); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	watch(strings.TrimSpace(ip), strings.TrimSpace(id), strings.TrimSpace(password))
+}
+
+// client3 starts the server, connects the player and creates a bot which plays with strategy.
+func client3(strategy string) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer s.close()
+	s.hints = true
+	go s.serve()
+
+	ip := net.JoinHostPort("localhost", s.port())
+	ready := make(chan struct{})
+	go connect(ip, protocol.HelloBody{}, ready)
+	<-ready
+	if !s.isWaiting() {
+		return // the player couldn't connect
+	}
+	startBot(ip, strategy)
+}
+
+// host runs a server with the given settings which pairs the connecting players until it is stopped.
+// If the seed isn't 0 the deals of the matches are decided by seed, seed+1 and so on.
+// If the server is rated the deals are shuffled so that they cannot be predicted.
+// If saveDir isn't empty the matches are saved there and resumed when the players come back.
+func host(addr string, config settings) {
+	if config.rated && config.seeded {
+		fmt.Println("A rated server cannot repeat the deals from a seed.")
+		return
+	}
+	s, err := startServer(addr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	s.settings = config
+	if s.saveDir != "" {
+		if err := s.load(); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	fmt.Println("Listening on " + s.listener.Addr().String())
+	s.serve()
+}
+
+// commands maps what the player can write to the messages sent to the server.
+var commands = map[string]protocol.Type{
+	Exchange: protocol.Exchange,
+	Close:    protocol.Close,
+	Stop:     protocol.Stop,
+	Hint:     protocol.Hint,
+	Quit:     protocol.Quit,
+}
+
+// emotes maps the names of protocol.Emotes to what they say.
+var emotes = map[string]string{
+	"hi":     "Hi!",
+	"gg":     "Good game!",
+	"wp":     "Well played!",
+	"nice":   "Nice marriage!",
+	"oops":   "Oops!",
+	"thanks": "Thanks!",
+}
+
+// reconnectTime is how long the client tries to get back to its seat after the connection is lost.
+const reconnectTime = time.Minute
+
+// dialServer connects to the server on ip and introduces the client with hello.
+func dialServer(ip string, hello protocol.HelloBody) (net.Conn, *protocol.Conn, error) {
+	connection, err := net.Dial("tcp", ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := protocol.NewConn(connection)
+	if _, err := conn.HandshakeWith(hello); err != nil {
+		connection.Close()
+		return nil, nil, err
+	}
+	return connection, conn, nil
+}
+
+// redial tries to get back to the seat of token until reconnectTime passes.
+func redial(ip, token string) (net.Conn, *protocol.Conn, error) {
+	deadline := time.Now().Add(reconnectTime)
+	for {
+		connection, conn, err := dialServer(ip, protocol.HelloBody{Token: token})
+		if err == nil || time.Now().After(deadline) {
+			return connection, conn, err
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// readLines reads what the player writes line by line until there is nothing more to read.
+func readLines() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for {
+			input, err := stdin.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- input
+		}
+	}()
+	return lines
+}
 
-	connection.Write([]byte(Connect))
+// connect creates a client-server connection and communicates through it.
+// If the connection is lost, it connects again and the player goes on from his seat.
+// If hello has a token, the player comes back to the saved match with it.
+// If hello asks for the lobby, what the player writes goes to the lobby until
+// he sits at a table. The messages are shown while the player writes,
+// so he sees how much time he has left. If ready isn't nil, it is closed
+// when the player waits for an opponent or when connect returns.
+func connect(ip string, hello protocol.HelloBody, ready chan struct{}) {
+	signal := func() {
+		if ready != nil {
+			close(ready)
+			ready = nil
+		}
+	}
+	defer signal()
 
-	buff := make([]byte, 256)
-	reader := bufio.NewReader(os.Stdin)
-	var input string
+	connection, conn, err := dialServer(ip, hello)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	token := hello.Token
+	inLobby := hello.Lobby && conn.Version() >= protocol.Lobbies
+	if hello.Lobby && !inLobby {
+		fmt.Print(NoLobby)
+	} else if inLobby {
+		fmt.Print(LobbyCommands)
+	}
+	defer func() {
+		connection.Close()
+	}()
 
-	if singlePlayer {
-		wg.Done()
+	deals, err := fair.NewPlayer()
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
+	done := make(chan struct{})
+	defer close(done)
+	messages := make(chan protocol.Received)
+	go conn.Listen(messages, done)
+	lines := readLines()
 
+	seat := sixtysix.Nobody
+	back := false   // the player has come back after the connection was lost
+	asking := false // the player is asked what to do
+	var state protocol.StateBody
 	for {
-		size, err := connection.Read(buff)
+		var r protocol.Received
+		select {
+		case input, ok := <-lines:
+			text := strings.TrimSpace(input) // as written, for the names and what the player says
+			input = strings.ToLower(text)
+			if !ok || input == Quit {
+				conn.Send(protocol.Quit, nil)
+				forgetResume()
+				return
+			}
+			if inLobby {
+				lobbyInput(conn, text)
+				continue
+			}
+			if chatInput(conn, text) {
+				if asking {
+					fmt.Print(YourTurn)
+				}
+				continue
+			}
+			if !asking {
+				fmt.Print(OpponentTurn)
+				continue
+			}
+			asking = !sendInput(conn, state.Hand, input)
+			continue
+		case r = <-messages:
+		}
+
+		message, err := r.Message, r.Err
+		if err != nil && token != "" && seat != sixtysix.Nobody {
+			fmt.Print(Reconnecting)
+			if newConnection, newConn, err := redial(ip, token); err == nil {
+				connection.Close()
+				connection, conn, back, asking = newConnection, newConn, true, false
+				go conn.Listen(messages, done)
+				continue
+			}
+		}
 		if err != nil {
 			if err == io.EOF {
 				fmt.Print(OpponentLeft)
 			} else {
 				fmt.Println(err)
 			}
+			if _, err := loadResume(); err == nil {
+				fmt.Print(CanResume)
+			}
 			return
 		}
-		message := string(buff)[:size]
-		fmt.Print(message)
-
-		for strings.Contains(message, YourTurn) || message == WrongInput || message == NotPossible {
-			input, err = reader.ReadString('\n')
-			for err != nil {
-				fmt.Println(TryAgain)
-				input, err = reader.ReadString('\n')
+		if err := deals.Handle(conn, message); err != nil {
+			if _, ok := err.(*fair.Unfair); !ok {
+				fmt.Println(err)
+				return
 			}
+			fmt.Println(Warning + err.Error())
+		} else if message.Type == protocol.DealReveal {
+			fmt.Print(FairDeal)
+		}
 
-			if len(input) == 2 && input[0] >= '1' && input[0] <= '6' {
-				connection.Write([]byte(input))
+		switch message.Type {
+		case protocol.Waiting:
+			fmt.Print(Waiting)
+			signal() // the bot can connect now
+		case protocol.Start:
+			var start protocol.StartBody
+			message.Decode(&start)
+			seat, inLobby = start.Seat, false
+			switch {
+			case back:
+				fmt.Print(Reconnected)
+			case start.Resumed:
+				fmt.Print(Resumed)
+			default:
+				fmt.Print(Start)
+			}
+			token = start.Token
+			if start.Match != "" && !back {
+				fmt.Print(Match + start.Match + CanWatch)
+			}
+			if start.Target != 0 && start.Target != sixtysix.WinningPoints && !back {
+				fmt.Print(PlayedTo + strconv.Itoa(start.Target) + " points.\n")
+			}
+			if start.Saved {
+				if err := saveResume(resumeInfo{Address: ip, Token: token}); err != nil {
+					fmt.Println(err)
+				}
+			}
+		case protocol.State:
+			state = protocol.StateBody{}
+			message.Decode(&state)
+			asking = state.Turn == seat
+			if !asking {
+				if !hasPlayed(state.Hand) {
+					fmt.Print(stateMsg(state, seat))
+				}
+				fmt.Print(OpponentTurn)
 				break
 			}
 
-			input = strings.ToLower(input[:strings.Index(input, "\n")])
-			switch input {
-			case Close:
-				connection.Write([]byte(Close))
-			case Exchange:
-				connection.Write([]byte(Exchange))
-			case Stop:
-				connection.Write([]byte(Stop))
-			case Help:
-				connection.Write([]byte(Help))
-			case Quit:
-				connection.Write([]byte(Quit))
+			if state.Table == sixtysix.NoCard {
+				fmt.Print(stateMsg(state, seat)) // otherwise nothing has changed since the last one
+			} else if state.Clock != nil {
+				fmt.Print(clockMsg(state.Clock, seat))
+			}
+			fmt.Print(YourTurn)
+		case protocol.Played:
+			var played protocol.PlayedBody
+			message.Decode(&played)
+			fmt.Print(playedMsg(played, seat))
+		case protocol.Closed:
+			if isOpponent(message, seat) {
+				fmt.Print(OpponentClosed)
+			}
+		case protocol.Exchanged:
+			if isOpponent(message, seat) {
+				fmt.Print(OpponentExchanged)
+			}
+		case protocol.TrickResult, protocol.DealResult, protocol.GameResult:
+			var result protocol.ResultBody
+			message.Decode(&result)
+			fmt.Print(resultMsg(message.Type, result, seat))
+			if message.Type == protocol.GameResult {
+				forgetResume()
 				return
-			default:
+			}
+		case protocol.Error:
+			var e protocol.ErrorBody
+			message.Decode(&e)
+			if e.Code == protocol.UnknownMatch {
+				fmt.Println(e.Message)
+				forgetResume()
+				return
+			}
+			if state.Turn != seat {
+				fmt.Println(e.Message)
+				break
+			}
+
+			if e.Code == protocol.InvalidCard {
 				fmt.Print(WrongInput)
+			} else {
+				fmt.Print(NotPossible)
+			}
+			asking = true
+		case protocol.Solution:
+			var solution protocol.SolutionBody
+			message.Decode(&solution)
+			fmt.Print(solutionMsg(solution, seat) + YourTurn)
+			asking = true
+		case protocol.TimeWarning:
+			var left protocol.ClockBody
+			message.Decode(&left)
+			fmt.Print("\n" + HurryUp + clockMsg(&left, seat))
+			if asking {
+				fmt.Print(YourTurn)
+			}
+		case protocol.TimeOut:
+			var out protocol.TimeOutBody
+			message.Decode(&out)
+			fmt.Print(timeOutMsg(out, seat))
+			if out.Seat == seat {
+				asking = false
+			}
+		case protocol.OpponentAway:
+			fmt.Print(OpponentAway)
+		case protocol.OpponentBack:
+			fmt.Print(OpponentBack)
+		case protocol.OpponentUnstable:
+			fmt.Print(OpponentUnstable)
+		case protocol.Said:
+			var said protocol.SaidBody
+			message.Decode(&said)
+			fmt.Print(saidMsg(said, seat))
+			if asking {
+				fmt.Print(YourTurn)
+			}
+		case protocol.Tables:
+			var tables protocol.TablesBody
+			message.Decode(&tables)
+			fmt.Print(tablesMsg(tables))
+		case protocol.Invited:
+			var invitation protocol.TableBody
+			message.Decode(&invitation)
+			fmt.Print(invitation.Host + InvitesYou + tableMsg(invitation) + JoinIt + invitation.Name + "\n")
+		case protocol.OpponentLeft:
+			fmt.Print(OpponentLeft)
+			if _, err := loadResume(); err == nil {
+				fmt.Print(CanResume)
+			}
+			return
+		}
+	}
+}
+
+// watch connects to the server on ip and shows the match with id to the player,
+// with the hands after each deal if he has the password of the commentators.
+func watch(ip, id, password string) {
+	connection, err := net.Dial("tcp", ip)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer connection.Close()
+	conn := protocol.NewConn(connection)
+	if _, err := conn.HandshakeWith(protocol.HelloBody{Watch: id, Commentator: password != "", Password: password}); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	messages := make(chan protocol.Received)
+	go conn.Listen(messages, done)
+	lines := readLines()
+	for {
+		var r protocol.Received
+		select {
+		case input, ok := <-lines:
+			input = strings.ToLower(strings.TrimSpace(input))
+			if !ok || input == Quit {
+				conn.Send(protocol.Quit, nil)
+				return
+			}
+			if input == Mute || input == Unmute {
+				conn.Send(protocol.Mute, protocol.MuteBody{Muted: input == Mute})
+				fmt.Print(muteMsg(input == Mute))
 				continue
 			}
+			fmt.Print(OnlyWatching)
+			continue
+		case r = <-messages:
+		}
+		if r.Err != nil {
+			if r.Err == io.EOF {
+				fmt.Print(WatchEnded)
+			} else {
+				fmt.Println(r.Err)
+			}
+			return
+		}
 
-			break
+		message := r.Message
+		switch message.Type {
+		case protocol.Start:
+			var start protocol.StartBody
+			message.Decode(&start)
+			fmt.Print(Watching + id + "\n")
+			if start.Target != 0 && start.Target != sixtysix.WinningPoints {
+				fmt.Print(PlayedTo + strconv.Itoa(start.Target) + " points.\n")
+			}
+		case protocol.View:
+			var view protocol.ViewBody
+			message.Decode(&view)
+			if view.Table[sixtysix.Player1] == sixtysix.NoCard && view.Table[sixtysix.Player2] == sixtysix.NoCard {
+				fmt.Print(viewMsg(view))
+			}
+		case protocol.Played:
+			var played protocol.PlayedBody
+			message.Decode(&played)
+			fmt.Print(seatName(played.Seat) + Plays + printable(played.Card))
+			if played.Marriage != 0 {
+				fmt.Print(" Marriage: " + strconv.Itoa(played.Marriage))
+			}
+			fmt.Println()
+		case protocol.Closed, protocol.Exchanged:
+			var who protocol.SeatBody
+			message.Decode(&who)
+			if message.Type == protocol.Closed {
+				fmt.Print(seatName(who.Seat) + Closes)
+			} else {
+				fmt.Print(seatName(who.Seat) + Exchanges)
+			}
+		case protocol.TrickResult, protocol.DealResult, protocol.GameResult:
+			var result protocol.ResultBody
+			message.Decode(&result)
+			fmt.Print(seatName(result.Winner) + watchResultMsg(message.Type, result))
+			if message.Type == protocol.GameResult {
+				return
+			}
+		case protocol.TimeOut:
+			var out protocol.TimeOutBody
+			message.Decode(&out)
+			fmt.Print(seatName(out.Seat) + TimeRanOut + penaltyMsg(out.Penalty))
+		case protocol.Said:
+			var said protocol.SaidBody
+			message.Decode(&said)
+			fmt.Print(seatName(said.Seat) + ": " + chatText(said) + "\n")
+		case protocol.OpponentLeft:
+			fmt.Print(PlayerLeft)
+			return
+		case protocol.Error:
+			var e protocol.ErrorBody
+			message.Decode(&e)
+			fmt.Println(e.Message)
+			if e.Code == protocol.UnknownMatch {
+				return
+			}
 		}
+	}
+}
 
-		if message == OpponentLeft {
+// lobbyInput sends what the player wants to do in the lobby to the server.
+func lobbyInput(conn *protocol.Conn, input string) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		fmt.Print(LobbyCommands)
+		return
+	}
+	fields[0] = strings.ToLower(fields[0])
+
+	switch {
+	case fields[0] == List && len(fields) == 1:
+		conn.Send(protocol.List, nil)
+	case fields[0] == Join && len(fields) == 2:
+		conn.Send(protocol.Join, protocol.JoinBody{Name: fields[1]})
+	case fields[0] == CreateTable && len(fields) >= 2:
+		if table, ok := tableSettings(fields[1], fields[2:]); ok {
+			conn.Send(protocol.Create, table)
+			return
+		}
+		fmt.Print(LobbyCommands)
+	case fields[0] == Invite && len(fields) >= 3:
+		if table, ok := tableSettings(fields[2], fields[3:]); ok {
+			table.Invite = fields[1]
+			conn.Send(protocol.Create, table)
 			return
 		}
+		fmt.Print(LobbyCommands)
+	default:
+		fmt.Print(LobbyCommands)
+	}
+}
+
+// tableSettings returns the table called name with the settings the player has written:
+// the points which win the game and the time control, both optional.
+func tableSettings(name string, settings []string) (protocol.TableBody, bool) {
+	table := protocol.TableBody{Name: name}
+	for _, setting := range settings {
+		if points, err := strconv.Atoi(setting); err == nil && table.Target == 0 {
+			table.Target = points
+		} else if strings.Contains(setting, "/") && table.Clock == "" {
+			table.Clock = setting
+		} else {
+			return table, false
+		}
+	}
+	return table, true
+}
+
+// chatInput sends what the player says or whether he mutes his opponent.
+// It returns false if the input isn't about chat.
+func chatInput(conn *protocol.Conn, input string) bool {
+	command := strings.ToLower(input)
+	if !strings.HasPrefix(command, SayCommand+" ") && command != Mute && command != Unmute && emotes[command] == "" {
+		return false
+	}
+	if !conn.Knows(protocol.Say) {
+		fmt.Print(NoChat)
+		return true
+	}
+
+	switch {
+	case strings.HasPrefix(command, SayCommand+" "):
+		conn.Send(protocol.Say, protocol.SayBody{Text: strings.TrimSpace(input[len(SayCommand):])})
+	case command == Mute || command == Unmute:
+		conn.Send(protocol.Mute, protocol.MuteBody{Muted: command == Mute})
+		fmt.Print(muteMsg(command == Mute))
+	default:
+		conn.Send(protocol.Say, protocol.SayBody{Emote: command})
+	}
+	return true
+}
+
+// sendInput sends what the player wants to do to the server.
+// It returns false if the input is wrong and the player is asked again.
+func sendInput(conn *protocol.Conn, hand []deck.Card, input string) bool {
+	if len(input) == 1 && input[0] >= '1' && int(input[0]-'1') < len(hand) {
+		card := hand[input[0]-'1']
+		if card == sixtysix.NoCard {
+			fmt.Print(WrongInput)
+			return false
+		}
+		conn.Send(protocol.Play, protocol.PlayBody{Card: card})
+		return true
+	}
+
+	if card, err := notation.Parse(input); err == nil && inHand(card, hand) {
+		conn.Send(protocol.Play, protocol.PlayBody{Card: card})
+		return true
+	}
+
+	if input == Help {
+		fmt.Print(Commands + YourTurn)
+		return false
+	}
+
+	command, ok := commands[input]
+	if !ok {
+		fmt.Print(WrongInput)
+		return false
+	}
+	conn.Send(command, nil)
+	return true
+}
+
+// inHand returns true if card is in hand.
+func inHand(card deck.Card, hand []deck.Card) bool {
+	for _, c := range hand {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPlayed returns true if there is a hole in the hand left by a card on the table.
+func hasPlayed(hand []deck.Card) bool {
+	for _, card := range hand {
+		if card == sixtysix.NoCard {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpponent returns true if message is about the opponent of seat.
+func isOpponent(message protocol.Message, seat int) bool {
+	var who protocol.SeatBody
+	return message.Decode(&who) == nil && who.Seat != seat
+}
+
+// stateMsg returns printable info about the hand, the deck and the points.
+func stateMsg(state protocol.StateBody, seat int) string {
+	return "\nYour hand: " + printable(state.Hand...) +
+		"\nTrump: " + printable(state.Trump) +
+		"\tDeck size: " + strconv.Itoa(state.DeckSize) +
+		"\tClosed: " + strconv.FormatBool(state.Closed) +
+		"\nDeal points: " + strconv.Itoa(state.Score) +
+		"\tGame points: " + strconv.Itoa(state.GameScore[seat]) +
+		":" + strconv.Itoa(state.GameScore[sixtysix.OpponentOf(seat)]) + "\n" +
+		clockMsg(state.Clock, seat)
+}
+
+// clockMsg returns printable info about the time left or nothing if the time isn't limited.
+func clockMsg(left *protocol.ClockBody, seat int) string {
+	if left == nil {
+		return ""
+	}
+	msg := "Time left:"
+	if left.Move >= 0 {
+		msg += " move " + minutes(left.Move)
+	}
+	if left.Game[seat] >= 0 {
+		msg += " game " + minutes(left.Game[seat]) + ":" + minutes(left.Game[sixtysix.OpponentOf(seat)])
+	}
+	return msg + "\n"
+}
+
+// minutes returns ms milliseconds written like 1m05s.
+func minutes(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+}
+
+// timeOutMsg returns printable info about whose time has run out and his penalty.
+func timeOutMsg(out protocol.TimeOutBody, seat int) string {
+	if out.Seat != seat {
+		return OpponentTimeOut + penaltyMsg(out.Penalty)
+	}
+	return YourTimeOut + penaltyMsg(out.Penalty)
+}
+
+// penaltyMsg returns printable info about the penalty with the given name.
+func penaltyMsg(penalty string) string {
+	switch penalty {
+	case clock.AutoPlay.String():
+		return PenaltyPlay
+	case clock.ForfeitDeal.String():
+		return PenaltyDeal
+	}
+	return PenaltyGame
+}
+
+// viewMsg returns printable info about the deck and both players for a spectator.
+func viewMsg(view protocol.ViewBody) string {
+	msg := "\nTrump: " + printable(view.Trump) +
+		"\tDeck size: " + strconv.Itoa(view.DeckSize) +
+		"\tClosed: " + strconv.FormatBool(view.Closed) + "\n"
+	for seat := range view.Score {
+		msg += seatName(seat) + ": deal points " + strconv.Itoa(view.Score[seat]) +
+			", game points " + strconv.Itoa(view.GameScore[seat])
+		if seat < len(view.Hands) {
+			msg += ", hand " + printable(view.Hands[seat]...)
+		}
+		msg += "\n"
+	}
+	return msg + clockMsg(view.Clock, sixtysix.Player1)
+}
+
+// tablesMsg returns printable info about the tables in the lobby.
+func tablesMsg(tables protocol.TablesBody) string {
+	if len(tables.Tables) == 0 {
+		return NoTables
+	}
+	msg := OpenTables
+	for _, table := range tables.Tables {
+		msg += "* " + table.Name + ", " + tableMsg(table)
+		if table.Host != "" {
+			msg += ", by " + table.Host
+		}
+		msg += "\n"
+	}
+	return msg
+}
+
+// tableMsg returns printable info about the settings of a table.
+func tableMsg(table protocol.TableBody) string {
+	msg := strconv.Itoa(table.Target) + " points"
+	if table.Clock != "" {
+		msg += ", clock " + table.Clock
+	}
+	return msg
+}
+
+// saidMsg returns printable info about what a player has said.
+func saidMsg(said protocol.SaidBody, seat int) string {
+	if said.Seat == seat {
+		return You + chatText(said) + "\n"
+	}
+	return Opponent + chatText(said) + "\n"
+}
+
+// chatText returns the text or the emote which has been said.
+func chatText(said protocol.SaidBody) string {
+	if text, ok := emotes[said.Emote]; ok {
+		return text
+	}
+	return said.Text
+}
+
+// muteMsg returns printable info about whether the player has muted the others.
+func muteMsg(muted bool) string {
+	if muted {
+		return Muted
+	}
+	return Unmuted
+}
+
+// seatName returns how seat is called for a spectator.
+func seatName(seat int) string {
+	return "Player " + strconv.Itoa(seat+1)
+}
+
+// watchResultMsg returns printable info for a spectator about what a player has won.
+func watchResultMsg(t protocol.Type, result protocol.ResultBody) string {
+	switch t {
+	case protocol.TrickResult:
+		return WatchTrick
+	case protocol.DealResult:
+		return WatchDeal + strconv.Itoa(result.Points) + "\n"
+	}
+	return WatchGame
+}
+
+// playedMsg returns printable info about a played card.
+func playedMsg(played protocol.PlayedBody, seat int) string {
+	marriage := ""
+	if played.Marriage != 0 {
+		marriage = "Marriage: " + strconv.Itoa(played.Marriage) + "\n"
+	}
+
+	if played.Seat == seat {
+		return marriage
 	}
+	if marriage != "" {
+		return OpponentCard + printable(played.Card) + " " + marriage
+	}
+	return OpponentCard + printable(played.Card) + "\n"
 }
 
-// main starts the game.
+// resultMsg returns printable info about who won a trick, a deal or the game.
+func resultMsg(t protocol.Type, result protocol.ResultBody, seat int) string {
+	won := result.Winner == seat
+	pts := strconv.Itoa(result.Points) + "\n"
+	switch {
+	case t == protocol.TrickResult && won:
+		return WonTrick
+	case t == protocol.TrickResult:
+		return LostTrick
+	case t == protocol.DealResult && won:
+		return WonDeal + pts
+	case t == protocol.DealResult:
+		return LostDeal + pts
+	case won:
+		return WonGame
+	}
+	return LostGame
+}
+
+// solutionMsg returns printable info about the best play and how the deal ends after it.
+func solutionMsg(solution protocol.SolutionBody, seat int) string {
+	msg := HintStop
+	if !solution.Stop {
+		msg = HintPlay + printable(solution.Card)
+	}
+	if solution.Winner == seat {
+		return msg + ". " + HintWin + strconv.Itoa(solution.Points) + "\n"
+	}
+	return msg + ". " + HintLose + strconv.Itoa(solution.Points) + "\n"
+}
+
+// printable returns the cards in the notation of the player separated by spaces.
+func printable(cards ...deck.Card) string {
+	return notation.FormatCards(cards)
+}
+
+// main starts the game or only a server or a bot if an address is given.
 func main() {
+	listen := flag.String("listen", "", "only run a server which pairs players, e.g. :6666")
+	seed := flag.Int64("seed", 0, "with -listen, repeat the deals of the matches from this seed")
+	rated := flag.Bool("rated", false, "with -listen, shuffle with crypto/rand so that the deals cannot be predicted")
+	saveDir := flag.String("save", "", "with -listen, save the matches in this directory after every trick, so the players can resume them")
+	grace := flag.Duration("grace", defaultGrace, "with -listen, how long a player who has lost the connection keeps his seat")
+	password := flag.String("commentators", "", "with -listen, the password of the commentators, who see the hands after each deal")
+	timeControl := flag.String("clock", "", "with -listen, the time for a move and for the game and the penalty when it runs out, e.g. 30s/10m/play; the penalty is play (the lowest card), deal or game (forfeit it)")
+	botIP := flag.String("bot", "", "only connect a bot to the server on ip:port")
+	strategy := flag.String("strategy", bot.Names[len(bot.Names)-1], "strategy of the bot: "+strings.Join(bot.Names, ", ")+" or exec:command to start an engine")
+	flag.DurationVar(&thinkTime, "think", thinkTime, "the longest time the expert bot thinks before a move, 0 for no limit if -iterations isn't 0")
+	flag.IntVar(&iterations, "iterations", iterations, "the most deals the expert bot tries before a move, 0 for no limit if -think isn't 0")
+	notationName := flag.String("notation", notation.Name, "how the cards are written: "+strings.Join(deck.NotationNames(), ", "))
+	flag.Parse()
+
+	if notation = deck.FindNotation(*notationName); notation == nil {
+		fmt.Println("Unknown notation " + *notationName)
+		os.Exit(2)
+	}
+	if thinkTime == 0 && iterations == 0 {
+		fmt.Println("The expert bot would never stop thinking, -think and -iterations cannot both be 0")
+		os.Exit(2)
+	}
+
+	if *listen != "" {
+		config := settings{seed: *seed, seeded: *seed != 0, rated: *rated, saveDir: *saveDir, grace: *grace, timeout: handshakeTimeout, heartbeat: protocol.HeartbeatInterval, password: *password}
+		if *timeControl != "" {
+			var err error
+			if config.control, err = clock.ParseControl(*timeControl); err != nil {
+				fmt.Println(err)
+				os.Exit(2)
+			}
+		}
+		host(*listen, config)
+		return
+	}
+	if *botIP != "" {
+		startBot(*botIP, *strategy)
+		return
+	}
 	menu()
 }
@@ -0,0 +1,175 @@
+// Package clock keeps the time of both players in a game like a chess clock.
+//
+// Only the clock of the player in turn runs. A player has Move for every move
+// and Game for all of his moves in the game. When either runs out, the penalty
+// of the time control is given to him.
+package clock
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Penalty is what happens to a player whose time has run out.
+type Penalty int
+
+// Penalties.
+const (
+	AutoPlay    Penalty = iota // the lowest legal card is played for him
+	ForfeitDeal                // he loses the deal
+	ForfeitGame                // he loses the game
+)
+
+var penaltyNames = [3]string{"play", "deal", "game"}
+
+// String returns the name of the penalty.
+func (p Penalty) String() string {
+	if p < AutoPlay || p > ForfeitGame {
+		return "?"
+	}
+	return penaltyNames[p]
+}
+
+// ParsePenalty returns the penalty with the given name.
+func ParsePenalty(s string) (Penalty, error) {
+	for p, name := range penaltyNames {
+		if s == name {
+			return Penalty(p), nil
+		}
+	}
+	return AutoPlay, errors.New("Unknown penalty " + s + ", expected one of " + strings.Join(penaltyNames[:], ", "))
+}
+
+// Warning is how long before his time runs out a player is warned.
+const Warning = 10 * time.Second
+
+// Control is the time control of a game. A zero Move or Game is no limit.
+type Control struct {
+	Move    time.Duration
+	Game    time.Duration
+	Penalty Penalty
+}
+
+// IsSet returns true if the time is limited.
+func (c Control) IsSet() bool {
+	return c.Move > 0 || c.Game > 0
+}
+
+// String returns the control written like move/game/penalty, e.g. 30s/10m0s/play.
+func (c Control) String() string {
+	return c.Move.String() + "/" + c.Game.String() + "/" + c.Penalty.String()
+}
+
+// ParseControl reads a control written like move/game/penalty, where
+// the penalty can be left out for AutoPlay and 0 is no limit.
+func ParseControl(s string) (Control, error) {
+	var c Control
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return c, errors.New("Expected move/game/penalty, e.g. 30s/10m/play")
+	}
+
+	var err error
+	if c.Move, err = time.ParseDuration(parts[0]); err != nil || c.Move < 0 {
+		return c, errors.New("Bad time for a move: " + parts[0])
+	}
+	if c.Game, err = time.ParseDuration(parts[1]); err != nil || c.Game < 0 {
+		return c, errors.New("Bad time for the game: " + parts[1])
+	}
+	if len(parts) == 3 {
+		c.Penalty, err = ParsePenalty(parts[2])
+	}
+	return c, err
+}
+
+// Clock is the clock of a game.
+type Clock struct {
+	Control Control
+
+	game    [2]time.Duration // left of Game for each player when his move has started
+	running int              // the player whose time runs or -1
+	since   time.Time        // when the move of running has started
+}
+
+// New returns a stopped clock with the whole time of control for both players.
+func New(control Control) *Clock {
+	return Restore(control, [2]time.Duration{control.Game, control.Game})
+}
+
+// Restore returns a stopped clock on which the players have game left of Game.
+func Restore(control Control, game [2]time.Duration) *Clock {
+	return &Clock{Control: control, game: game, running: -1}
+}
+
+// Start stops the clock of the player who is running and starts a new move of player.
+func (c *Clock) Start(player int, now time.Time) {
+	c.Stop(now)
+	c.running, c.since = player, now
+}
+
+// Stop stops the clock of the player who is running.
+func (c *Clock) Stop(now time.Time) {
+	if c.running >= 0 {
+		c.game[c.running] -= now.Sub(c.since)
+		c.running = -1
+	}
+}
+
+// Running returns the player whose time runs or -1.
+func (c *Clock) Running() int {
+	return c.running
+}
+
+// Left returns how much is left of the move of the running player and of Game for
+// each player. The times which aren't limited are negative.
+func (c *Clock) Left(now time.Time) (time.Duration, [2]time.Duration) {
+	move, game := time.Duration(-1), [2]time.Duration{-1, -1}
+	if c.Control.Game > 0 {
+		game = c.game
+		if c.running >= 0 {
+			game[c.running] -= now.Sub(c.since)
+		}
+		for player := range game {
+			if game[player] < 0 {
+				game[player] = 0
+			}
+		}
+	}
+	if c.Control.Move > 0 && c.running >= 0 {
+		move = c.Control.Move - now.Sub(c.since)
+		if move < 0 {
+			move = 0
+		}
+	}
+	return move, game
+}
+
+// Deadline returns when the time of the running player runs out.
+// It returns false if the clock is stopped or the time isn't limited.
+func (c *Clock) Deadline() (time.Time, bool) {
+	if c.running < 0 || !c.Control.IsSet() {
+		return time.Time{}, false
+	}
+
+	deadline := c.since.Add(c.game[c.running])
+	if c.Control.Game <= 0 || c.Control.Move > 0 && c.Control.Move < c.game[c.running] {
+		deadline = c.since.Add(c.Control.Move)
+	}
+	return deadline, true
+}
+
+// WarningAt returns when the running player should be warned: Warning before
+// his time runs out or halfway to it if he has less than twice Warning.
+// It returns false if the clock is stopped or the time isn't limited.
+func (c *Clock) WarningAt() (time.Time, bool) {
+	deadline, ok := c.Deadline()
+	if !ok {
+		return deadline, false
+	}
+	before := Warning
+	if left := deadline.Sub(c.since); left < 2*Warning {
+		before = left / 2
+	}
+	return deadline.Add(-before), true
+}
@@ -0,0 +1,60 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseControl(t *testing.T) {
+	c, err := ParseControl("30s/10m/deal")
+	if err != nil || c.Move != 30*time.Second || c.Game != 10*time.Minute || c.Penalty != ForfeitDeal {
+		t.Error("ParseControl error!", c, err)
+	}
+	if again, err := ParseControl(c.String()); err != nil || again != c {
+		t.Error("String error!", c.String(), err)
+	}
+	if c, err := ParseControl("0/5m"); err != nil || c.Move != 0 || c.Penalty != AutoPlay || !c.IsSet() {
+		t.Error("ParseControl error!", c, err)
+	}
+	for _, s := range []string{"", "30s", "30s/x", "-1s/1m", "1s/1m/nap", "1s/1m/play/1"} {
+		if _, err := ParseControl(s); err == nil {
+			t.Error("Expected bad control!", s)
+		}
+	}
+}
+
+func TestClock(t *testing.T) {
+	start := time.Now()
+	c := New(Control{Move: 30 * time.Second, Game: time.Minute})
+	if _, ok := c.Deadline(); ok {
+		t.Error("The stopped clock has a deadline!")
+	}
+
+	c.Start(0, start)
+	if deadline, ok := c.Deadline(); !ok || !deadline.Equal(start.Add(30*time.Second)) {
+		t.Error("Deadline error!", deadline)
+	}
+
+	// player 0 uses 50 seconds in two moves, so only 10 are left of his game
+	c.Start(1, start.Add(25*time.Second))
+	c.Start(0, start.Add(26*time.Second))
+	c.Start(1, start.Add(51*time.Second))
+	c.Start(0, start.Add(52*time.Second))
+	move, game := c.Left(start.Add(55 * time.Second))
+	if move != 27*time.Second || game != [2]time.Duration{7 * time.Second, 58 * time.Second} {
+		t.Error("Left error!", move, game)
+	}
+	if deadline, _ := c.Deadline(); !deadline.Equal(start.Add(62 * time.Second)) {
+		t.Error("Deadline error!", deadline)
+	}
+	if warning, _ := c.WarningAt(); !warning.Equal(start.Add(57 * time.Second)) {
+		t.Error("WarningAt error!", warning)
+	}
+
+	moveOnly := New(Control{Move: time.Second})
+	moveOnly.Start(1, start)
+	move, game = moveOnly.Left(start.Add(2 * time.Second))
+	if deadline, _ := moveOnly.Deadline(); move != 0 || game[0] >= 0 || !deadline.Equal(start.Add(time.Second)) {
+		t.Error("Move only error!", move, game, deadline)
+	}
+}
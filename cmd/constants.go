@@ -1,36 +1,71 @@
 package main
 
 const (
-	Player1 = 0
-	Player2 = 1
-	Nobody  = 2
+	// commands the player can write
 
-	NoCard = ""
-	Rank   = 0
-	Suit   = 1
-
-	LastTrickBonus = 10
-
-	// client -> server
-
-	Connect  = "connect"
 	Exchange = "exchange"
 	Close    = "close"
 	Stop     = "stop"
 	Help     = "help"
+	Hint     = "hint"
 	Quit     = "quit"
 
-	// server -> client
+	// commands for chat
+
+	SayCommand = "say"
+	Mute       = "mute"
+	Unmute     = "unmute"
+
+	// commands in the lobby
+
+	List        = "list"
+	CreateTable = "create"
+	Invite      = "invite"
+	Join        = "join"
+
+	// texts shown to the player
 
 	Waiting           = "Waiting for the other player to connect.\n"
 	Start             = "The game starts now.\n\n"
-	YourTurn          = "It's your turn, pick a card number (1-6) or write a command: "
+	Resumed           = "The saved game goes on. Its deals cannot be checked.\n\n"
+	CanResume         = "The game is saved, pick \"Resume game\" to go on with it later.\n"
+	YourTurn          = "It's your turn, pick a card number (1-6), write a card or a command: "
 	OpponentTurn      = "It's your opponent's turn, please wait.\n"
 	OpponentCard      = "Opponent's card: "
 	OpponentLeft      = "Opponent left.\n"
 	OpponentClosed    = "Opponent closed.\n"
 	OpponentExchanged = "Opponent exchanged the trump.\n"
-	TryAgain          = "Something went wrong! Please try again."
+	OpponentAway      = "Opponent lost the connection, waiting for him to come back.\n"
+	OpponentBack      = "Opponent is back.\n"
+	OpponentUnstable  = "Opponent's connection is unstable.\n"
+	Match             = "This is match "
+	CanWatch          = ", others can watch it with \"Watch game\".\n"
+	Watching          = "You are watching match "
+	OnlyWatching      = "You are only watching, write quit to leave.\n"
+	WatchEnded        = "The match has ended.\n"
+	PlayerLeft        = "A player left.\n"
+	Plays             = " plays "
+	Closes            = " closed.\n"
+	Exchanges         = " exchanged the trump.\n"
+	WatchTrick        = " won the trick.\n"
+	WatchDeal         = " won the deal. Points: "
+	WatchGame         = " WON THE GAME!\n"
+	TimeRanOut        = "'s time ran out. "
+	NoLobby           = "The server has no lobby, you will play with the next player who connects.\n"
+	LobbyCommands     = "Lobby commands:\n* list\n* create <table> [points] [clock], e.g. create friday 7 30s/10m\n* invite <player> <table> [points] [clock]\n* join <table>\n* quit\n"
+	NoTables          = "There are no open tables, create one.\n"
+	OpenTables        = "Open tables:\n"
+	InvitesYou        = " invites you to play to "
+	JoinIt            = ", write: join "
+	PlayedTo          = "The game is played to "
+	Searching         = "Looking for games on the local network.\n"
+	You               = "You: "
+	Opponent          = "Opponent: "
+	NoChat            = "The server doesn't pass on what the players say.\n"
+	Muted             = "The others are muted.\n"
+	Unmuted           = "The others aren't muted anymore.\n"
+	Reconnecting      = "Connection lost, trying to get back to the game.\n"
+	Reconnected       = "You are back in the game.\n\n"
 	WrongInput        = "Wrong input, try again: "
 	WonTrick          = "You won this trick.\n"
 	WonDeal           = "You won this deal. Points: "
@@ -39,5 +74,18 @@ const (
 	LostDeal          = "You lost this deal. Opponents gets: "
 	LostGame          = "You lost the game.\n"
 	NotPossible       = "Operation not possible. Try something else: "
-	Commands          = "Commands:\n* exchange\n* close\n* stop\n* quit\n"
+	FairDeal          = "The cards of this deal were shuffled fairly.\n"
+	Warning           = "WARNING: "
+	HurryUp           = "Hurry up! "
+	YourTimeOut       = "Your time ran out. "
+	OpponentTimeOut   = "Opponent's time ran out. "
+	PenaltyPlay       = "The lowest card is played.\n"
+	PenaltyDeal       = "The deal is lost.\n"
+	PenaltyGame       = "The game is lost.\n"
+	HintPlay          = "Hint: play "
+	HintStop          = "Hint: stop the deal"
+	HintWin           = "You will win the deal. Points: "
+	HintLose          = "You will lose the deal. Opponent gets: "
+	Commands          = "Commands:\n* exchange\n* close\n* stop\n* hint (after the deck is empty, against a bot)\n* quit\n" +
+		"* say <text>, at any time\n* hi, gg, wp, nice, oops, thanks to say it quickly\n* mute, unmute what the opponent says\n"
 )
@@ -0,0 +1,198 @@
+package deck
+
+import (
+	"errors"
+	"strings"
+)
+
+// Suit is the suit of a card.
+type Suit byte
+
+// Suits in the order of the ordered deck.
+const (
+	Clubs Suit = iota + 1
+	Diamonds
+	Hearts
+	Spades
+)
+
+// Suits are all suits in the order of the ordered deck.
+var Suits = [4]Suit{Clubs, Diamonds, Hearts, Spades}
+
+var suitNames = [5]string{"", "♣", "♦", "♥", "♠"}
+
+// String returns the symbol of the suit.
+func (s Suit) String() string {
+	if s < Clubs || s > Spades {
+		return "?"
+	}
+	return suitNames[s]
+}
+
+// Rank is the rank of a card. A higher rank takes a lower one of the same suit.
+type Rank byte
+
+// Ranks from the lowest to the highest.
+const (
+	Nine Rank = iota + 1
+	Jack
+	Queen
+	King
+	Ten
+	Ace
+)
+
+// Ranks are all ranks from the lowest to the highest.
+var Ranks = [6]Rank{Nine, Jack, Queen, King, Ten, Ace}
+
+var (
+	rankNames  = [7]string{"", "9", "J", "Q", "K", "X", "A"} // X == 10
+	rankPoints = [7]int{0, 0, 2, 3, 4, 10, 11}
+)
+
+// String returns the letter of the rank, X for ten.
+func (r Rank) String() string {
+	if r < Nine || r > Ace {
+		return "?"
+	}
+	return rankNames[r]
+}
+
+// Points returns how many points a card with rank r is worth.
+func (r Rank) Points() int {
+	if r < Nine || r > Ace {
+		return 0
+	}
+	return rankPoints[r]
+}
+
+// Card is a card of the deck. The zero value is NoCard, which marks
+// an empty place in a hand or on the table.
+type Card byte
+
+// NoCard is the empty place of a card.
+const NoCard Card = 0
+
+// ErrBadCard is returned when a card cannot be parsed.
+var ErrBadCard = errors.New("Unknown card")
+
+// NewCard returns the card with rank r and suit s.
+func NewCard(r Rank, s Suit) Card {
+	return Card(r)<<4 | Card(s)
+}
+
+// Rank returns the rank of c.
+func (c Card) Rank() Rank {
+	return Rank(c >> 4)
+}
+
+// Suit returns the suit of c.
+func (c Card) Suit() Suit {
+	return Suit(c & 0xf)
+}
+
+// IsValid returns true if c is one of the 24 cards of the deck.
+func (c Card) IsValid() bool {
+	return c.Rank() >= Nine && c.Rank() <= Ace && c.Suit() >= Clubs && c.Suit() <= Spades
+}
+
+// Points returns how many points c is worth.
+func (c Card) Points() int {
+	return c.Rank().Points()
+}
+
+// String returns the rank and the suit of c like X♠ or an empty string for NoCard.
+func (c Card) String() string {
+	if c == NoCard {
+		return ""
+	}
+	return c.Rank().String() + c.Suit().String()
+}
+
+// Compare returns -1, 0 or 1 if c is before, the same or after other in the ordered deck.
+// The cards are ordered by rank first and then by suit.
+func (c Card) Compare(other Card) int {
+	switch {
+	case c < other:
+		return -1
+	case c > other:
+		return 1
+	}
+	return 0
+}
+
+// Parse returns the card written like X♠ or 10♠. It returns ErrBadCard
+// for anything else, even for an empty string.
+func Parse(s string) (Card, error) {
+	if strings.HasPrefix(s, "10") {
+		s = "X" + s[2:]
+	}
+	if s == "" {
+		return NoCard, ErrBadCard
+	}
+	for _, r := range Ranks {
+		if s[:1] != rankNames[r] {
+			continue
+		}
+		for _, suit := range Suits {
+			if s[1:] == suitNames[suit] {
+				return NewCard(r, suit), nil
+			}
+		}
+	}
+	return NoCard, ErrBadCard
+}
+
+// MustParse returns the card written in s and panics if it cannot be parsed.
+// It is meant for cards written in the code.
+func MustParse(s string) Card {
+	c, err := Parse(s)
+	if err != nil {
+		panic("deck: " + err.Error() + ": " + s)
+	}
+	return c
+}
+
+// ParseCards returns the cards written in s separated by spaces,
+// where - stands for NoCard.
+func ParseCards(s string) ([]Card, error) {
+	var cards []Card
+	for _, field := range strings.Fields(s) {
+		if field == "-" {
+			cards = append(cards, NoCard)
+			continue
+		}
+		c, err := Parse(field)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+// MarshalText writes c like X♠ or nothing for NoCard.
+func (c Card) MarshalText() ([]byte, error) {
+	if c != NoCard && !c.IsValid() {
+		return nil, ErrBadCard
+	}
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText reads a card written like X♠ or in any of Notations,
+// or nothing for NoCard.
+func (c *Card) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = NoCard
+		return nil
+	}
+	card, err := Parse(string(text))
+	for idx := 0; err != nil && idx < len(Notations); idx++ {
+		card, err = Notations[idx].Parse(string(text))
+	}
+	if err != nil {
+		return err
+	}
+	*c = card
+	return nil
+}
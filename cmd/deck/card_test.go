@@ -0,0 +1,113 @@
+package deck
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, card := range OrderedDeck {
+		if c, err := Parse(card.String()); err != nil || c != card {
+			t.Error("Parse error!", card, err)
+		}
+	}
+	if c, err := Parse("10♥"); err != nil || c != NewCard(Ten, Hearts) {
+		t.Error("Parse error!", c, err)
+	}
+	for _, s := range []string{"", "X", "♠", "1♠", "B♠", "X♠♠", "XS", "x♠"} {
+		if _, err := Parse(s); err != ErrBadCard {
+			t.Error("Expected bad card!", s)
+		}
+	}
+}
+
+func TestPointsAndCompare(t *testing.T) {
+	total := 0
+	for i, card := range OrderedDeck {
+		total += card.Points()
+		if i > 0 && card.Compare(OrderedDeck[i-1]) != 1 {
+			t.Error("Compare error!", card)
+		}
+	}
+	if total != 120 || NewCard(Ten, Clubs).Points() != 10 || NoCard.Points() != 0 {
+		t.Error("Points error!", total)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	hand := []Card{MustParse("X♠"), NoCard, MustParse("A♥")}
+	encoded, err := json.Marshal(hand)
+	if err != nil || string(encoded) != `["X♠","","A♥"]` {
+		t.Error("Marshal error!", string(encoded), err)
+	}
+
+	var decoded []Card
+	if err := json.Unmarshal(encoded, &decoded); err != nil || len(decoded) != 3 ||
+		decoded[0] != hand[0] || decoded[1] != NoCard || decoded[2] != hand[2] {
+		t.Error("Unmarshal error!", decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`["TS","ah","Д♥"]`), &decoded); err != nil ||
+		decoded[0] != hand[0] || decoded[1] != hand[2] || decoded[2] != MustParse("Q♥") {
+		t.Error("Expected the cards in any notation!", decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`["Z♠"]`), &decoded); err == nil {
+		t.Error("Expected bad card!")
+	}
+	if _, err := json.Marshal(Card(0xff)); err == nil {
+		t.Error("Expected bad card!")
+	}
+}
+
+func TestParseCards(t *testing.T) {
+	cards, err := ParseCards("9♣ - A♠")
+	if err != nil || len(cards) != 3 || cards[0] != OrderedDeck[0] || cards[1] != NoCard || cards[2] != OrderedDeck[Size-1] {
+		t.Error("ParseCards error!", cards, err)
+	}
+	if _, err := ParseCards("9♣ 9"); err != ErrBadCard {
+		t.Error("Expected bad card!", err)
+	}
+}
+
+func TestNotations(t *testing.T) {
+	queen := NewCard(Queen, Hearts)
+	ten := NewCard(Ten, Spades)
+	for _, test := range []struct {
+		n          *Notation
+		queen, ten string
+	}{
+		{Unicode, "Q♥", "10♠"},
+		{ASCII, "QH", "TS"},
+		{Cards, "🂽", "🂪"},
+		{Bulgarian, "Д♥", "10♠"},
+		{German, "D♥", "10♠"},
+	} {
+		if test.n.Format(queen) != test.queen || test.n.FormatCards([]Card{ten, NoCard}) != test.ten+" " {
+			t.Error("Format error!", test.n.Name, test.n.Format(queen), test.n.Format(ten))
+		}
+		if FindNotation(test.n.Name) != test.n {
+			t.Error("FindNotation error!", test.n.Name)
+		}
+
+		seen := make(map[string]bool)
+		for _, card := range OrderedDeck {
+			name := test.n.Format(card)
+			if c, err := test.n.Parse(name); err != nil || c != card || seen[name] {
+				t.Error("Parse error!", test.n.Name, name, err)
+			}
+			seen[name] = true
+			if c, err := test.n.Parse(card.String()); err != nil || c != card {
+				t.Error("Parse error!", test.n.Name, card, err)
+			}
+		}
+		if _, err := test.n.Parse("Z"); err != ErrBadCard {
+			t.Error("Expected bad card!", test.n.Name)
+		}
+	}
+
+	if c, err := ASCII.Parse("ts"); err != nil || c != ten {
+		t.Error("Parse error!", c, err)
+	}
+	if FindNotation("klingon") != nil {
+		t.Error("FindNotation error!")
+	}
+}
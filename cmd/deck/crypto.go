@@ -0,0 +1,36 @@
+package deck
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand"
+)
+
+// CryptoSource is a math/rand source which reads from crypto/rand.
+// Its numbers cannot be predicted, so it is used for rated games,
+// but they cannot be repeated either and Seed does nothing.
+type CryptoSource struct{}
+
+// Int63 returns a non-negative random 63-bit integer.
+func (CryptoSource) Int63() int64 {
+	return int64(CryptoSource{}.Uint64() >> 1)
+}
+
+// Uint64 returns a random 64-bit integer.
+func (CryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("deck: crypto/rand failed: " + err.Error())
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// Seed does nothing because the numbers come from the operating system.
+func (CryptoSource) Seed(int64) {}
+
+// NewSecure creates an ordered deck whose shuffles cannot be predicted.
+func NewSecure() *Deck {
+	return NewWithSource(CryptoSource{})
+}
+
+var _ mathrand.Source64 = CryptoSource{}
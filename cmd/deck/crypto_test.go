@@ -0,0 +1,60 @@
+package deck
+
+import (
+	"math"
+	"testing"
+)
+
+// chiSquare shuffles d n times and returns the chi-square statistic of how
+// many times each card has been on each of the 24 positions and its degrees of freedom.
+func chiSquare(d *Deck, n int) (float64, int) {
+	var counts [Size][Size]int
+	index := make(map[Card]int)
+	for i, card := range OrderedDeck {
+		index[card] = i
+	}
+
+	for i := 0; i < n; i++ {
+		d.Shuffle()
+		for pos, card := range d.Current {
+			counts[index[card]][pos]++
+		}
+	}
+
+	expected := float64(n) / Size
+	chi := 0.0
+	for _, row := range counts {
+		for _, count := range row {
+			diff := float64(count) - expected
+			chi += diff * diff / expected
+		}
+	}
+	return chi, (Size - 1) * (Size - 1)
+}
+
+// critical returns the chi-square value with dof degrees of freedom which is exceeded
+// with probability about 0.001, by the Wilson-Hilferty approximation.
+func critical(dof int) float64 {
+	k := float64(dof)
+	const z = 3.09
+	return k * math.Pow(1-2/(9*k)+z*math.Sqrt(2/(9*k)), 3)
+}
+
+func TestShuffleIsUniform(t *testing.T) {
+	for name, d := range map[string]*Deck{"seeded": NewSeeded(1), "secure": NewSecure()} {
+		chi, dof := chiSquare(d, 24000)
+		if limit := critical(dof); chi > limit {
+			t.Error("Shuffle is not uniform!", name, chi, limit)
+		}
+	}
+}
+
+func TestCryptoSource(t *testing.T) {
+	var s CryptoSource
+	if s.Int63() < 0 {
+		t.Error("Expected a non-negative number!")
+	}
+	if s.Uint64() == s.Uint64() && s.Uint64() == s.Uint64() {
+		t.Error("Expected different numbers!")
+	}
+}
@@ -10,78 +10,79 @@ import (
 // Size is the number of cards in a deck.
 const Size = 24
 
-var (
-	suits  = [4]string{"♣", "♦", "♥", "♠"}
-	cards  = [6]string{"9", "J", "Q", "K", "X", "A"} // X == 10
-	points = [6]int{0, 2, 3, 4, 10, 11}
-)
-
 // OrderedDeck is the initial full ordered deck.
-// Points is a map connecting each card with its points.
-var (
-	OrderedDeck []string
-	Points      map[byte]int
-)
+var OrderedDeck []Card
 
-// init initializes the exported OrderedDeck and Points.
+// init initializes the exported OrderedDeck.
 func init() {
-	OrderedDeck = make([]string, Size)
-	Points = make(map[byte]int)
-	i := 0
-	for idx, card := range cards {
-		for _, suit := range suits {
-			OrderedDeck[i] = card + suit
-			i++
+	OrderedDeck = make([]Card, 0, Size)
+	for _, rank := range Ranks {
+		for _, suit := range Suits {
+			OrderedDeck = append(OrderedDeck, NewCard(rank, suit))
 		}
-		Points[card[0]] = points[idx]
 	}
 }
 
 // Deck contains the original deck and the current one (after drawing cards).
 type Deck struct {
-	Initial []string
-	Current []string
+	Initial []Card
+	Current []Card
+
+	rand *rand.Rand // decides the shuffles
 }
 
-// New creates and returns an ordered deck of cards.
+// New creates and returns an ordered deck of cards which is shuffled
+// differently every time the program runs.
 func New() *Deck {
-	deck := new(Deck)
+	return NewWithSource(rand.NewSource(time.Now().UnixNano()))
+}
+
+// NewWithSource creates an ordered deck of cards whose shuffles are decided by src.
+// The global source of math/rand isn't used. src must not be used by others
+// because it isn't safe for concurrent use.
+func NewWithSource(src rand.Source) *Deck {
+	deck := &Deck{rand: rand.New(src)}
 
-	deck.Initial = make([]string, Size)
-	deck.Current = make([]string, Size)
+	deck.Initial = make([]Card, Size)
+	deck.Current = make([]Card, Size)
 	copy(deck.Initial, OrderedDeck)
 	copy(deck.Current, deck.Initial)
 
 	return deck
 }
 
+// NewSeeded creates an ordered deck whose shuffles are decided by seed,
+// so two decks with the same seed are shuffled the same way.
+func NewSeeded(seed int64) *Deck {
+	return NewWithSource(rand.NewSource(seed))
+}
+
 // Shuffle shuffles the Initial deck and makes a copy of it in Current.
 func (d *Deck) Shuffle() {
-	res := make([]string, Size)
-	rand.Seed(time.Now().UTC().UnixNano())
-	perm := rand.Perm(Size)
+	res := make([]Card, Size)
+	perm := d.rand.Perm(Size)
 	for i, v := range perm {
 		res[v] = d.Initial[i]
 	}
 
 	copy(d.Initial, res)
 	if len(d.Current) < Size {
-		d.Current = make([]string, Size)
+		d.Current = make([]Card, Size)
 	}
 	copy(d.Current, res)
 }
 
 // DrawCard returns the top card of the deck if it has any.
-func (d *Deck) DrawCard() (string, error) {
+func (d *Deck) DrawCard() (Card, error) {
 	card, err := d.DrawNcards(1)
 	if err != nil {
-		return "", err
+		return NoCard, err
 	}
 	return card[0], err
 }
 
 // DrawNcards returns the top n cards if n <= the size of the current deck.
-func (d *Deck) DrawNcards(n int) ([]string, error) {
+func (d *Deck) DrawNcards(n int) ([]Card, error) {
 	if n > len(d.Current) {
 		return nil, errors.New("Not enough cards in deck")
 	}
@@ -92,11 +93,11 @@ func (d *Deck) DrawNcards(n int) ([]string, error) {
 }
 
 // AreTheSameSuit returns true if card1 and card2 are from the same suit.
-func AreTheSameSuit(card1, card2 string) bool {
-	return card1[1:] == card2[1:]
+func AreTheSameSuit(card1, card2 Card) bool {
+	return card1.Suit() == card2.Suit()
 }
 
 // HasHigherRank returns true if the rank of card1 is higher than the rank of card2.
-func HasHigherRank(card1, card2 string) bool {
-	return Points[card1[0]] > Points[card2[0]]
+func HasHigherRank(card1, card2 Card) bool {
+	return card1.Rank() > card2.Rank()
 }
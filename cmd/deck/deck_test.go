@@ -1,6 +1,9 @@
 package deck
 
-import "testing"
+import (
+	"math/rand"
+	"testing"
+)
 
 func TestShuffle(t *testing.T) {
 	d := New()
@@ -21,6 +24,19 @@ func TestShuffle(t *testing.T) {
 	t.Error("Didn't shuffle!")
 }
 
+func TestNewSeeded(t *testing.T) {
+	d1, d2 := NewSeeded(66), NewSeeded(66)
+	for i := 0; i < 3; i++ {
+		d1.Shuffle()
+		d2.Shuffle()
+		for j := range d1.Current {
+			if d1.Current[j] != d2.Current[j] {
+				t.Fatal("Seeded decks differ!")
+			}
+		}
+	}
+}
+
 func TestDrowCard(t *testing.T) {
 	d := New()
 	d.Shuffle()
@@ -79,3 +95,23 @@ func TestAreTheSameSuit(t *testing.T) {
 		t.Error("Suit error!")
 	}
 }
+
+// countingSource counts how many numbers have been taken from it.
+type countingSource struct {
+	rand.Source
+	count int
+}
+
+func (s *countingSource) Int63() int64 {
+	s.count++
+	return s.Source.Int63()
+}
+
+func TestNewWithSource(t *testing.T) {
+	src := &countingSource{Source: rand.NewSource(1)}
+	d := NewWithSource(src)
+	d.Shuffle()
+	if src.count == 0 {
+		t.Error("Expected the deck to use its source!")
+	}
+}
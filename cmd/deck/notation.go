@@ -0,0 +1,91 @@
+package deck
+
+import "strings"
+
+// Notation is a way to write the cards for people or for other programs.
+type Notation struct {
+	Name  string
+	names map[Card]string
+}
+
+// newNotation returns the notation which writes a card as the name of its rank and then its suit.
+func newNotation(name string, ranks [7]string, suits [5]string) *Notation {
+	n := &Notation{Name: name, names: make(map[Card]string)}
+	for _, rank := range Ranks {
+		for _, suit := range Suits {
+			n.names[NewCard(rank, suit)] = ranks[rank] + suits[suit]
+		}
+	}
+	return n
+}
+
+// cardBlock returns the notation with the pictures of the cards from Unicode.
+func cardBlock() *Notation {
+	// the rows of the block start from spades and the columns are the ranks: 1 is the ace, A is the ten
+	suits := [5]rune{0, 0x1F0D0, 0x1F0C0, 0x1F0B0, 0x1F0A0}
+	ranks := [7]rune{0, 9, 0xB, 0xD, 0xE, 0xA, 1}
+
+	n := &Notation{Name: "cards", names: make(map[Card]string)}
+	for _, rank := range Ranks {
+		for _, suit := range Suits {
+			n.names[NewCard(rank, suit)] = string(suits[suit] + ranks[rank])
+		}
+	}
+	return n
+}
+
+// The notations.
+var (
+	Unicode   = newNotation("unicode", [7]string{"", "9", "J", "Q", "K", "10", "A"}, suitNames)
+	ASCII     = newNotation("ascii", [7]string{"", "9", "J", "Q", "K", "T", "A"}, [5]string{"", "C", "D", "H", "S"})
+	Cards     = cardBlock()
+	Bulgarian = newNotation("bg", [7]string{"", "9", "В", "Д", "П", "10", "А"}, suitNames)
+	German    = newNotation("de", [7]string{"", "9", "B", "D", "K", "10", "A"}, suitNames)
+)
+
+// Notations are all notations, Unicode first.
+var Notations = []*Notation{Unicode, ASCII, Cards, Bulgarian, German}
+
+// NotationNames returns the names of all notations.
+func NotationNames() []string {
+	names := make([]string, len(Notations))
+	for idx, n := range Notations {
+		names[idx] = n.Name
+	}
+	return names
+}
+
+// FindNotation returns the notation with the given name or nil.
+func FindNotation(name string) *Notation {
+	for _, n := range Notations {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// Format returns card written in n or an empty string for NoCard.
+func (n *Notation) Format(card Card) string {
+	return n.names[card]
+}
+
+// FormatCards returns the cards written in n separated by spaces.
+func (n *Notation) FormatCards(cards []Card) string {
+	names := make([]string, len(cards))
+	for idx, card := range cards {
+		names[idx] = n.Format(card)
+	}
+	return strings.Join(names, " ")
+}
+
+// Parse returns the card written in n in upper or lower case.
+// It also accepts the cards written like X♠, as Card.String writes them.
+func (n *Notation) Parse(s string) (Card, error) {
+	for card, name := range n.names {
+		if strings.EqualFold(s, name) {
+			return card, nil
+		}
+	}
+	return Parse(s)
+}
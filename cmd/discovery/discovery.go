@@ -0,0 +1,195 @@
+// Package discovery finds the games hosted on the local network.
+//
+// A host announces its game with a small UDP datagram to the broadcast address
+// of every network it is on and to the loopback address every Interval. The
+// others listen on Port for a while and connect to the address the datagram
+// came from with the port of the game. A game heard from several addresses of
+// its host is told apart by the random id of its announcements and its port.
+// Nothing leaves the local network.
+package discovery
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Port is the UDP port the games are announced on.
+const Port = 6667
+
+// Interval is how often a game is announced.
+const Interval = time.Second
+
+// magic tells the announcements of sixtySix apart from other datagrams on Port.
+const magic = "sixtySix"
+
+// Game is a game hosted on the local network. Address is ip:port of its server,
+// which the players who hear the announcement connect to.
+type Game struct {
+	Name    string
+	Address string
+}
+
+// announcement is the datagram a host sends.
+type announcement struct {
+	Magic string `json:"game"`
+	Name  string `json:"name"`
+	Port  int    `json:"port"`
+	ID    string `json:"id,omitempty"` // random for each host, so its games are heard once
+}
+
+// newID returns a random id of the announcements of a host.
+func newID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// Addresses returns where the games are announced: the broadcast address of
+// every IPv4 network the computer is on and the loopback address, all with port.
+func Addresses(port int) []string {
+	addrs := []string{net.JoinHostPort("127.0.0.1", strconv.Itoa(port))}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return addrs
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagBroadcast == 0 {
+			continue
+		}
+		networks, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, network := range networks {
+			ipNet, ok := network.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLoopback() {
+				continue
+			}
+			broadcast := make(net.IP, net.IPv4len)
+			for i, b := range ipNet.IP.To4() {
+				broadcast[i] = b | ^ipNet.Mask[len(ipNet.Mask)-net.IPv4len+i]
+			}
+			addrs = append(addrs, net.JoinHostPort(broadcast.String(), strconv.Itoa(port)))
+		}
+	}
+	return addrs
+}
+
+// Announce sends the name and the port of a game to addrs every Interval
+// until stop is closed. The addresses which cannot be reached are skipped.
+func Announce(addrs []string, name string, port int, stop <-chan struct{}) error {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	datagram, err := json.Marshal(announcement{Magic: magic, Name: name, Port: port, ID: id})
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		for _, addr := range addrs {
+			if udpAddr, err := net.ResolveUDPAddr("udp4", addr); err == nil {
+				conn.WriteTo(datagram, udpAddr)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// Browser hears the games announced on the local network.
+type Browser struct {
+	conn net.PacketConn
+}
+
+// Listen starts hearing the announcements sent to addr, usually ":6667".
+func Listen(addr string) (*Browser, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Browser{conn: conn}, nil
+}
+
+// Addr returns the address the browser hears on.
+func (b *Browser) Addr() net.Addr {
+	return b.conn.LocalAddr()
+}
+
+// Close stops hearing the announcements.
+func (b *Browser) Close() error {
+	return b.conn.Close()
+}
+
+// Games returns the games heard in the next d sorted by their names.
+// Every game is returned once, with the first address of its host it is heard from.
+// The games of the hosts which don't send an id are told apart by their addresses.
+func (b *Browser) Games(d time.Duration) ([]Game, error) {
+	if err := b.conn.SetReadDeadline(time.Now().Add(d)); err != nil {
+		return nil, err
+	}
+
+	var games []Game
+	heard := make(map[string]bool)
+	buffer := make([]byte, 1024)
+	for {
+		n, from, err := b.conn.ReadFrom(buffer)
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			break
+		}
+		if err != nil {
+			return games, err
+		}
+
+		var a announcement
+		udpAddr, ok := from.(*net.UDPAddr)
+		if json.Unmarshal(buffer[:n], &a) != nil || a.Magic != magic || a.Port <= 0 || !ok {
+			continue
+		}
+		game := Game{Name: a.Name, Address: net.JoinHostPort(udpAddr.IP.String(), strconv.Itoa(a.Port))}
+		key := a.ID + "/" + strconv.Itoa(a.Port)
+		if a.ID == "" {
+			key = game.Address
+		}
+		if !heard[key] {
+			heard[key] = true
+			games = append(games, game)
+		}
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Name != games[j].Name {
+			return games[i].Name < games[j].Name
+		}
+		return games[i].Address < games[j].Address
+	})
+	return games, nil
+}
+
+// Browse returns the games announced on the local network in the next d.
+func Browse(d time.Duration) ([]Game, error) {
+	b, err := Listen(":" + strconv.Itoa(Port))
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return b.Games(d)
+}
@@ -0,0 +1,44 @@
+package discovery
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAnnounce(t *testing.T) {
+	b, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	// other datagrams on the port are skipped
+	if conn, err := net.Dial("udp4", b.Addr().String()); err == nil {
+		conn.Write([]byte(`{"game":"chess","name":"x","port":1}`))
+		conn.Close()
+	}
+	// another host may call its game the same and be heard from two of its addresses
+	udpAddr, _ := net.ResolveUDPAddr("udp4", b.Addr().String())
+	for _, ip := range []net.IP{net.IPv4(127, 0, 0, 2), net.IPv4(127, 0, 0, 3)} {
+		if conn, err := net.DialUDP("udp4", &net.UDPAddr{IP: ip}, udpAddr); err == nil {
+			conn.Write([]byte(`{"game":"sixtySix","name":"table","port":6666,"id":"other"}`))
+			conn.Close()
+		}
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go Announce([]string{b.Addr().String(), "no such host:1"}, "table", 6666, stop)
+
+	games, err := b.Games(3 * Interval / 2)
+	if err != nil || len(games) != 2 || games[0] != (Game{Name: "table", Address: "127.0.0.1:6666"}) ||
+		games[1] != (Game{Name: "table", Address: "127.0.0.2:6666"}) {
+		t.Error("Games error!", games, err)
+	}
+}
+
+func TestAddresses(t *testing.T) {
+	addrs := Addresses(Port)
+	if len(addrs) == 0 || addrs[0] != "127.0.0.1:6667" {
+		t.Error("Addresses error!", addrs)
+	}
+}
@@ -1,14 +1,22 @@
 /*
-Package main contains five files: game.go, server.go, client.go, bot.go, constants.go.
+Package main contains the server, the client and the bot of the game.
 
-game.go provides api for creating and managing a game of 66.
+server.go accepts the connecting players and pairs them into matches.
+lobby.go keeps the tables at which the players wait for each other.
 
-server.go is responsible the communication between the players and manages the game.
+match.go is responsible the communication between two players and manages their game.
+save.go writes the matches to disk and reads them back, chat.go relays what the
+players say and spectator.go shows them to the clients who only watch.
 
-client.go interacts with the player and communicates with the server.
+client.go interacts with the player, communicates with the server and renders its messages.
+It finds the games on the local network with the discovery package.
 
-bot.go is responsible for the singleplayer part of the game.
+bot.go is responsible for the singleplayer part of the game. The bot sees only
+what the server sends to it and plays with one of the strategies of the bot package.
 
-constants.go contains the messages used for communication between the players and the server.
+constants.go contains the commands of the player and the texts shown to him.
+The messages sent between the clients and the server are defined by the protocol package.
+
+The rules of the game live in the sixtysix package and the cards in the deck package.
 */
 package main
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// suits maps the suits of the cards to the letters the engines use.
+var suits = map[deck.Suit]string{deck.Clubs: "c", deck.Diamonds: "d", deck.Hearts: "h", deck.Spades: "s"}
+
+// ErrBadCard is returned for a card an engine has written wrong.
+var ErrBadCard = errors.New("Bad card")
+
+// ToASCII returns card the way the engines write it.
+func ToASCII(card deck.Card) string {
+	if !card.IsValid() {
+		return ""
+	}
+	return card.Rank().String() + suits[card.Suit()]
+}
+
+// FromASCII returns the card an engine has written.
+func FromASCII(card string) (deck.Card, error) {
+	if len(card) != 2 {
+		return deck.NoCard, ErrBadCard
+	}
+	for _, rank := range deck.Ranks {
+		if strings.ToUpper(card[:1]) != rank.String() {
+			continue
+		}
+		for suit, letter := range suits {
+			if strings.ToLower(card[1:]) == letter {
+				return deck.NewCard(rank, suit), nil
+			}
+		}
+	}
+	return deck.NoCard, ErrBadCard
+}
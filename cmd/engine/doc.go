@@ -0,0 +1,60 @@
+/*
+Package engine lets bots written as separate programs, engines, play Sixty-six.
+The host, the server or the tournament runner, starts the engine and talks to it
+through its standard input and output, one command per line, like chess engines
+speak UCI.
+
+Cards are written with two ASCII characters: the rank (9, J, Q, K, X for ten, A)
+and the suit (c, d, h, s), e.g. Xs is the ten of spades. Players are written as
+me or opponent. The engine must ignore the commands it doesn't know, so new ones
+can be added without breaking it.
+
+The host starts with
+
+	sixtysix 1
+
+where 1 is the version of the protocol. The engine may answer with
+
+	name <any text>
+
+and must answer with
+
+	ready
+
+Then the host sends what happens in the game:
+
+	newgame                            a game starts
+	newdeal                            a deal starts
+	hand <card> <card> ...             the cards in the hand of the engine
+	trump <card>                       the card which decides the trump suit
+	decksize <n>                       the cards left in the deck counting the trump
+	score <n>                          the points of the engine in the deal
+	gamescore <mine> <opponent's>      the game points
+	played <card> [<marriage>]         the engine has played card and announced a marriage
+	opponentplayed <card> [<marriage>] the opponent has played card and announced a marriage
+	closed me|opponent                 the deck has been closed
+	exchanged me|opponent              the nine of trumps has been exchanged
+	trick me|opponent <points>         the trick has been won
+	deal me|opponent <points>          the deal has been won and the game points for it
+	gameover me|opponent               the game has been won
+	error <text>                       the last answer of the engine was not possible, go follows
+	quit                               the engine must exit
+
+When it's the turn of the engine the host sends hand, trump, decksize, score and
+gamescore and then
+
+	go
+
+and the engine must answer with one of
+
+	play <card>
+	close
+	exchange
+	stop
+
+A marriage is announced by playing its queen or king when leading. After close
+and exchange the host sends go again. After a few answers in a row which are not
+possible the host plays the first card the engine can play. Lines starting with info are ignored by the
+host, so the engine can use them to explain what it thinks.
+*/
+package engine
@@ -0,0 +1,339 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// Version is the version of the engine protocol.
+const Version = 1
+
+// Prefix marks a strategy name which is a command starting an engine, e.g. "exec:./mybot -level 3".
+const Prefix = "exec:"
+
+// Timeouts used when MoveTime is not set.
+const (
+	HandshakeTimeout = 10 * time.Second
+	DefaultMoveTime  = 10 * time.Second
+)
+
+// Errors of the engines.
+var (
+	ErrTimeout = errors.New("The engine didn't answer in time")
+	ErrExited  = errors.New("The engine has exited")
+)
+
+// Engine is a bot in another program. It implements bot.Strategy and bot.Observer:
+// everything the server sends is passed on to the engine and when it's its turn
+// the engine is asked what to do. If the engine fails it plays the first legal card,
+// Err returns why and Fallbacks counts it. An engine which hasn't answered in time
+// or has exited isn't asked anymore.
+type Engine struct {
+	Name     string
+	MoveTime time.Duration // how long to wait for an answer to go, 0 for no limit
+
+	w      io.Writer
+	lines  chan string
+	done   chan struct{} // closed when the lines aren't read anymore
+	once   sync.Once
+	seat   int
+	target int // the points which win the game
+	err    error
+	fails  int // the moves played for the engine because it has failed
+	close  func() error
+}
+
+// New talks to an engine which reads from w and writes to r and waits until it is ready.
+func New(r io.Reader, w io.Writer) (*Engine, error) {
+	e := &Engine{
+		MoveTime: DefaultMoveTime,
+		w:        w,
+		lines:    make(chan string),
+		done:     make(chan struct{}),
+		seat:     sixtysix.Nobody,
+		target:   sixtysix.WinningPoints,
+	}
+	go e.read(r)
+
+	if err := e.send("sixtysix", Version); err != nil {
+		e.stop()
+		return nil, err
+	}
+	for {
+		line, err := e.readLine(HandshakeTimeout)
+		if err != nil {
+			e.stop()
+			return nil, err
+		}
+		if strings.HasPrefix(line, "name ") {
+			e.Name = strings.TrimSpace(line[len("name "):])
+		} else if line == "ready" {
+			return e, nil
+		}
+	}
+}
+
+// Start runs command with args as an engine.
+func Start(command string, args ...string) (*Engine, error) {
+	cmd := exec.Command(command, args...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e, err := New(out, in)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	e.close = func() error {
+		in.Close()
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(time.Second):
+			cmd.Process.Kill()
+			return <-done
+		}
+	}
+	return e, nil
+}
+
+// StartNamed starts the engine from a strategy name with Prefix.
+func StartNamed(name string) (*Engine, error) {
+	fields := strings.Fields(strings.TrimPrefix(name, Prefix))
+	if len(fields) == 0 {
+		return nil, errors.New("No engine command in " + name)
+	}
+	return Start(fields[0], fields[1:]...)
+}
+
+// read passes the lines written by the engine to e.lines until it exits
+// or nobody reads them anymore.
+func (e *Engine) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		select {
+		case e.lines <- strings.TrimSpace(scanner.Text()):
+		case <-e.done:
+			return
+		}
+	}
+	close(e.lines)
+}
+
+// stop tells read that nobody reads the lines anymore.
+func (e *Engine) stop() {
+	e.once.Do(func() {
+		close(e.done)
+	})
+}
+
+// readLine returns the next line written by the engine which isn't info or empty.
+func (e *Engine) readLine(timeout time.Duration) (string, error) {
+	var timer <-chan time.Time
+	if timeout != 0 {
+		timer = time.After(timeout)
+	}
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return "", ErrExited
+			}
+			if line != "" && line != "info" && !strings.HasPrefix(line, "info ") {
+				return line, nil
+			}
+		case <-timer:
+			return "", ErrTimeout
+		}
+	}
+}
+
+// send writes a command with its arguments to the engine.
+func (e *Engine) send(command string, args ...interface{}) error {
+	line := command
+	for _, arg := range args {
+		line += " " + fmt.Sprint(arg)
+	}
+	_, err := io.WriteString(e.w, line+"\n")
+	return err
+}
+
+// who returns me if player is the engine and opponent otherwise.
+func (e *Engine) who(player int) string {
+	if player == e.seat {
+		return "me"
+	}
+	return "opponent"
+}
+
+// Observe implements bot.Observer: it passes message on to the engine.
+func (e *Engine) Observe(message protocol.Message) error {
+	switch message.Type {
+	case protocol.Start:
+		var start protocol.StartBody
+		message.Decode(&start)
+		e.seat, e.target = start.Seat, start.Target
+		if e.target == 0 {
+			e.target = sixtysix.WinningPoints
+		}
+		if err := e.send("newgame"); err != nil {
+			return err
+		}
+		return e.send("newdeal")
+	case protocol.State:
+		var state protocol.StateBody
+		message.Decode(&state)
+		return e.sendState(state)
+	case protocol.Played:
+		var played protocol.PlayedBody
+		message.Decode(&played)
+		command := "played"
+		if played.Seat != e.seat {
+			command = "opponentplayed"
+		}
+		if played.Marriage != 0 {
+			return e.send(command, ToASCII(played.Card), played.Marriage)
+		}
+		return e.send(command, ToASCII(played.Card))
+	case protocol.Closed, protocol.Exchanged:
+		var who protocol.SeatBody
+		message.Decode(&who)
+		return e.send(message.Type.String(), e.who(who.Seat))
+	case protocol.TrickResult:
+		var result protocol.ResultBody
+		message.Decode(&result)
+		return e.send("trick", e.who(result.Winner), result.Points)
+	case protocol.DealResult:
+		var result protocol.ResultBody
+		message.Decode(&result)
+		if err := e.send("deal", e.who(result.Winner), result.Points); err != nil {
+			return err
+		}
+		if result.Score != nil && result.Score[sixtysix.Player1] < e.target &&
+			result.Score[sixtysix.Player2] < e.target {
+			return e.send("newdeal")
+		}
+	case protocol.GameResult:
+		var result protocol.ResultBody
+		message.Decode(&result)
+		return e.send("gameover", e.who(result.Winner))
+	case protocol.Error:
+		var body protocol.ErrorBody
+		message.Decode(&body)
+		return e.send("error", body.Message)
+	}
+	return nil
+}
+
+// sendState tells the engine what it can see now.
+func (e *Engine) sendState(state protocol.StateBody) error {
+	var hand []interface{}
+	for _, card := range state.Hand {
+		if card != sixtysix.NoCard {
+			hand = append(hand, ToASCII(card))
+		}
+	}
+	me := e.seat
+	if me == sixtysix.Nobody {
+		me = sixtysix.Player1
+	}
+
+	for _, err := range []error{
+		e.send("hand", hand...),
+		e.send("trump", ToASCII(state.Trump)),
+		e.send("decksize", state.DeckSize),
+		e.send("score", state.Score),
+		e.send("gamescore", state.GameScore[me], state.GameScore[sixtysix.OpponentOf(me)]),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Act implements bot.Strategy: it asks the engine what to do.
+func (e *Engine) Act(v *bot.View) bot.Action {
+	action, err := e.ask()
+	if err != nil {
+		e.err = err
+		e.fails++
+		cards := v.LegalCards()
+		if len(cards) == 0 {
+			return bot.Action{Type: bot.Stop}
+		}
+		return bot.Action{Type: bot.Play, Card: cards[0]}
+	}
+	return action
+}
+
+// ask sends go to the engine and reads its answer.
+func (e *Engine) ask() (bot.Action, error) {
+	if e.err == ErrExited || e.err == ErrTimeout {
+		return bot.Action{}, e.err
+	}
+	if err := e.send("go"); err != nil {
+		return bot.Action{}, err
+	}
+
+	line, err := e.readLine(e.MoveTime)
+	if err != nil {
+		return bot.Action{}, err
+	}
+	fields := strings.Fields(line)
+	switch {
+	case line == "close":
+		return bot.Action{Type: bot.Close}, nil
+	case line == "exchange":
+		return bot.Action{Type: bot.Exchange}, nil
+	case line == "stop":
+		return bot.Action{Type: bot.Stop}, nil
+	case len(fields) == 2 && fields[0] == "play":
+		card, err := FromASCII(fields[1])
+		return bot.Action{Type: bot.Play, Card: card}, err
+	}
+	return bot.Action{}, errors.New("Unknown answer: " + strconv.Quote(line))
+}
+
+// Err returns why the engine has failed last time or nil.
+func (e *Engine) Err() error {
+	return e.err
+}
+
+// Fallbacks returns how many times the engine has failed and a move has been played for it.
+func (e *Engine) Fallbacks() int {
+	return e.fails
+}
+
+// Close tells the engine to quit and waits for it to exit if it has been started by Start.
+func (e *Engine) Close() error {
+	e.send("quit")
+	e.stop()
+	if e.close != nil {
+		return e.close()
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// fake is an engine in the tests which answers go with answer and
+// passes all other lines it reads to lines.
+func fake(t *testing.T, answer string) (*Engine, chan string) {
+	hostR, engineW := io.Pipe()
+	engineR, hostW := io.Pipe()
+	lines := make(chan string, 100)
+	go func() {
+		scanner := bufio.NewScanner(engineR)
+		for scanner.Scan() {
+			switch line := scanner.Text(); line {
+			case "sixtysix 1":
+				io.WriteString(engineW, "name fake\ninfo hello\nready\n")
+			case "go":
+				if answer != "" {
+					io.WriteString(engineW, "info thinking\n"+answer+"\n")
+				}
+			default:
+				lines <- line
+			}
+		}
+	}()
+
+	e, err := New(hostR, hostW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e, lines
+}
+
+// message returns a message with type t and body for the tests.
+func message(t protocol.Type, body interface{}) protocol.Message {
+	m, _ := protocol.NewMessage(t, body)
+	return m
+}
+
+// expect reads the next line sent to the engine and checks it.
+func expect(t *testing.T, lines chan string, want string) {
+	select {
+	case line := <-lines:
+		if line != want {
+			t.Error("Expected "+want+", got", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected " + want)
+	}
+}
+
+// card and cards return the cards written in the tests.
+var card = deck.MustParse
+
+func cards(s string) []deck.Card {
+	c, err := deck.ParseCards(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestCards(t *testing.T) {
+	if ToASCII(card("X♠")) != "Xs" || ToASCII(card("9♦")) != "9d" {
+		t.Error("ToASCII error!")
+	}
+	if c, err := FromASCII("qh"); err != nil || c != card("Q♥") {
+		t.Error("FromASCII error!", c, err)
+	}
+	for _, bad := range []string{"", "Q", "Qx", "8h", "Qhh"} {
+		if _, err := FromASCII(bad); err == nil {
+			t.Error("Expected bad card!", bad)
+		}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	e, lines := fake(t, "")
+	if e.Name != "fake" {
+		t.Error("Name error!", e.Name)
+	}
+
+	e.Observe(message(protocol.Start, protocol.StartBody{Seat: sixtysix.Player2}))
+	expect(t, lines, "newgame")
+	expect(t, lines, "newdeal")
+
+	e.Observe(message(protocol.State, protocol.StateBody{
+		Hand: cards("Q♥ - X♠"), Trump: card("A♣"), DeckSize: 12, Score: 20, GameScore: [2]int{3, 1},
+	}))
+	expect(t, lines, "hand Qh Xs")
+	expect(t, lines, "trump Ac")
+	expect(t, lines, "decksize 12")
+	expect(t, lines, "score 20")
+	expect(t, lines, "gamescore 1 3")
+
+	e.Observe(message(protocol.Played, protocol.PlayedBody{Seat: sixtysix.Player1, Card: card("K♦"), Marriage: 20}))
+	expect(t, lines, "opponentplayed Kd 20")
+	e.Observe(message(protocol.Closed, protocol.SeatBody{Seat: sixtysix.Player2}))
+	expect(t, lines, "closed me")
+	e.Observe(message(protocol.TrickResult, protocol.ResultBody{Winner: sixtysix.Player1, Points: 14}))
+	expect(t, lines, "trick opponent 14")
+	score := [2]int{3, 4}
+	e.Observe(message(protocol.DealResult, protocol.ResultBody{Winner: sixtysix.Player2, Points: 3, Score: &score}))
+	expect(t, lines, "deal me 3")
+	expect(t, lines, "newdeal")
+}
+
+func TestAct(t *testing.T) {
+	v := bot.NewView()
+	v.Seat, v.Turn = sixtysix.Player1, sixtysix.Player1
+	v.Hand = cards("Q♥ X♠")
+
+	e, _ := fake(t, "play Xs")
+	if action := e.Act(v); action.Type != bot.Play || action.Card != card("X♠") || e.Err() != nil {
+		t.Error("Act error!", action, e.Err())
+	}
+
+	e, _ = fake(t, "close")
+	if action := e.Act(v); action.Type != bot.Close {
+		t.Error("Expected close!", action)
+	}
+
+	e, _ = fake(t, "dance")
+	if action := e.Act(v); action.Type != bot.Play || action.Card != card("Q♥") || e.Err() == nil {
+		t.Error("Expected the first legal card!", action)
+	}
+	if e.Fallbacks() != 1 {
+		t.Error("Expected a fallback!", e.Fallbacks())
+	}
+
+	e, _ = fake(t, "")
+	e.MoveTime = 10 * time.Millisecond
+	if action := e.Act(v); action.Card != card("Q♥") || e.Err() != ErrTimeout {
+		t.Error("Expected timeout!", action, e.Err())
+	}
+}
+
+func TestStartNamed(t *testing.T) {
+	if _, err := StartNamed(Prefix + "  "); err == nil || !strings.Contains(err.Error(), "No engine") {
+		t.Error("Expected no command!", err)
+	}
+	if _, err := StartNamed(Prefix + "/nonexistent/engine"); err == nil {
+		t.Error("Expected the engine not to start!")
+	}
+}
@@ -0,0 +1,41 @@
+// Command example is the simplest engine: it plays the first card from its hand
+// and the next one when that isn't possible. It shows how to speak the protocol
+// of the engine package, e.g.
+//
+//	cmd -bot ip:port -strategy "exec:go run ./engine/example"
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var hand []string
+	tries := 0
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "sixtysix":
+			fmt.Println("name example")
+			fmt.Println("ready")
+		case "hand":
+			hand = fields[1:]
+			tries = 0
+		case "error":
+			tries++
+		case "go":
+			fmt.Println("info trying card", tries+1)
+			fmt.Println("play " + hand[tries%len(hand)])
+		case "quit":
+			return
+		}
+	}
+}
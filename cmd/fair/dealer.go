@@ -0,0 +1,100 @@
+package fair
+
+import (
+	"encoding/json"
+	"math/rand"
+
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+)
+
+// Dealer keeps the secrets on the server.
+type Dealer struct {
+	Commitments protocol.CommitmentsBody
+
+	secret  string    // of the server
+	secrets [2]string // of the players
+}
+
+// NewDealer returns a dealer with a new secret of the server.
+func NewDealer() (*Dealer, error) {
+	secret, err := NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := Commit(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Dealer{secret: secret, Commitments: protocol.CommitmentsBody{Server: commitment}}, nil
+}
+
+// SetSecret keeps the secret of player. It returns ErrBadSecret if it doesn't
+// match the commitment of player.
+func (d *Dealer) SetSecret(player int, secret string) error {
+	commitment, err := Commit(secret)
+	if err != nil || commitment != d.Commitments.Players[player] {
+		return ErrBadSecret
+	}
+	d.secrets[player] = secret
+	return nil
+}
+
+// Reveal returns the secrets of deal number deal. It returns ErrBadDeal
+// if the secrets aren't enough for so many deals.
+func (d *Dealer) Reveal(deal int) (protocol.DealRevealBody, error) {
+	body := protocol.DealRevealBody{Deal: deal}
+	var err error
+	if body.Server, err = DealSecret(d.secret, deal); err != nil {
+		return body, err
+	}
+	for player, secret := range d.secrets {
+		if body.Players[player], err = DealSecret(secret, deal); err != nil {
+			return body, err
+		}
+	}
+	return body, nil
+}
+
+// Source returns the source which shuffles the deck of deal number deal.
+// It returns ErrBadDeal if the secrets aren't enough for so many deals.
+func (d *Dealer) Source(deal int) (rand.Source, error) {
+	secrets, err := d.Reveal(deal)
+	if err != nil {
+		return nil, err
+	}
+	return Source(secrets.Server, secrets.Players[0], secrets.Players[1]), nil
+}
+
+// savedDealer is a dealer written with its secrets.
+type savedDealer struct {
+	Commitments protocol.CommitmentsBody `json:"commitments"`
+	Secret      string                   `json:"secret"`
+	Secrets     [2]string                `json:"secrets"`
+}
+
+// MarshalJSON writes the dealer with all secrets, so it must be kept
+// only where the players cannot read it.
+func (d *Dealer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(savedDealer{Commitments: d.Commitments, Secret: d.secret, Secrets: d.secrets})
+}
+
+// UnmarshalJSON reads a dealer written by MarshalJSON. It returns ErrBadSecret
+// if the secrets don't match the commitments.
+func (d *Dealer) UnmarshalJSON(data []byte) error {
+	var saved savedDealer
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	if commitment, err := Commit(saved.Secret); err != nil || commitment != saved.Commitments.Server {
+		return ErrBadSecret
+	}
+
+	dealer := Dealer{Commitments: saved.Commitments, secret: saved.Secret}
+	for player, secret := range saved.Secrets {
+		if err := dealer.SetSecret(player, secret); err != nil {
+			return err
+		}
+	}
+	*d = dealer
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package fair lets the players check that the server hasn't stacked the deck.
+//
+// Before the first deal the server and both players pick secrets. The players
+// send only their commitments to the server. When it has both, it sends all
+// three commitments to the players and only then they send it their secrets,
+// so nobody can pick a secret after he has seen the others.
+//
+// The deck of every deal is shuffled from deck.OrderedDeck by a source made from
+// the secrets of the deal of all three. The secret of deal number k is the secret
+// hashed Deals-k times with SHA-256 and the commitment is the secret hashed Deals
+// times. After the deal the server reveals the secrets of the deal, so a player
+// can hash them k times to find the commitments and shuffle the deck himself,
+// but cannot find the secrets of the next deals from them.
+package fair
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	mathrand "math/rand"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// Deals is how many deals the secrets are enough for. A game played to n points
+// has 2n-1 deals at most because every deal gives at least a point to somebody,
+// so the secrets are enough for the games played to MaxTarget points.
+const Deals = 32
+
+// MaxTarget is the most points a game with fair deals can be played to.
+const MaxTarget = (Deals + 1) / 2
+
+// Errors returned for bad secrets and deals.
+var (
+	ErrBadSecret = errors.New("Bad secret")
+	ErrBadDeal   = errors.New("No such deal")
+)
+
+// NewSecret returns a random secret in hex.
+func NewSecret() (string, error) {
+	var b [sha256.Size]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// hash returns secret hashed the given number of times.
+func hash(secret string, times int) (string, error) {
+	b, err := hex.DecodeString(secret)
+	if err != nil || len(b) != sha256.Size {
+		return "", ErrBadSecret
+	}
+	for i := 0; i < times; i++ {
+		sum := sha256.Sum256(b)
+		b = sum[:]
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Commit returns the commitment to secret.
+func Commit(secret string) (string, error) {
+	return hash(secret, Deals)
+}
+
+// DealSecret returns the secret of deal number deal, starting from 1.
+func DealSecret(secret string, deal int) (string, error) {
+	if deal < 1 || deal > Deals {
+		return "", ErrBadDeal
+	}
+	return hash(secret, Deals-deal)
+}
+
+// Check returns true if dealSecret is the secret of deal number deal behind commitment.
+func Check(dealSecret, commitment string, deal int) bool {
+	if deal < 1 || deal > Deals {
+		return false
+	}
+	c, err := hash(dealSecret, deal)
+	return err == nil && c == commitment
+}
+
+// source is a math/rand source which hashes a key and a counter, so anyone
+// who knows the key gets the same numbers.
+type source struct {
+	key     [sha256.Size]byte
+	counter uint64
+}
+
+// Source returns the source which shuffles the deck of a deal with the given secrets
+// of the server and both players.
+func Source(dealSecrets ...string) mathrand.Source {
+	h := sha256.New()
+	for _, secret := range dealSecrets {
+		h.Write([]byte(secret))
+	}
+	s := &source{}
+	h.Sum(s.key[:0])
+	return s
+}
+
+// Int63 returns a non-negative 63-bit integer.
+func (s *source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Uint64 returns the next 64-bit integer.
+func (s *source) Uint64() uint64 {
+	var block [sha256.Size + 8]byte
+	copy(block[:], s.key[:])
+	binary.BigEndian.PutUint64(block[sha256.Size:], s.counter)
+	s.counter++
+	sum := sha256.Sum256(block[:])
+	return binary.BigEndian.Uint64(sum[:])
+}
+
+// Seed does nothing because the numbers are decided by the secrets.
+func (s *source) Seed(int64) {}
+
+// Deck returns the shuffled deck of a deal with the given secrets of the server
+// and both players, the first card on top.
+func Deck(dealSecrets ...string) []deck.Card {
+	d := deck.NewWithSource(Source(dealSecrets...))
+	d.Shuffle()
+	return d.Initial
+}
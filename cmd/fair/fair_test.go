@@ -0,0 +1,144 @@
+package fair
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+func TestCheck(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := Commit(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for deal := 1; deal <= 3; deal++ {
+		dealSecret, err := DealSecret(secret, deal)
+		if err != nil || !Check(dealSecret, commitment, deal) {
+			t.Error("Check error!", deal, err)
+		}
+		if Check(dealSecret, commitment, deal+1) {
+			t.Error("Check error!", deal)
+		}
+	}
+	if _, err := DealSecret(secret, Deals+1); err != ErrBadDeal {
+		t.Error("Deal error!", err)
+	}
+	if _, err := Commit("not hex"); err != ErrBadSecret {
+		t.Error("Secret error!", err)
+	}
+}
+
+func TestDeckMatchesGame(t *testing.T) {
+	dealer, err := NewDealer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for player := range dealer.Commitments.Players {
+		secret, _ := NewSecret()
+		dealer.Commitments.Players[player], _ = Commit(secret)
+		dealer.SetSecret(player, secret)
+	}
+	game := sixtysix.NewWithDeals(func(deal int) rand.Source {
+		source, _ := dealer.Source(deal)
+		return source
+	})
+	game.Start()
+
+	reveal, _ := dealer.Reveal(1)
+	cards := Deck(reveal.Server, reveal.Players[0], reveal.Players[1])
+	inTurn := append(append([]deck.Card(nil), cards[0:3]...), cards[6:9]...)
+	if game.Trump() != cards[12] || fmt.Sprint(game.Hand(game.PlayerInTurn())) != fmt.Sprint(inTurn) {
+		t.Error("The game isn't dealt from the deck!", cards, game.Hand(game.PlayerInTurn()))
+	}
+}
+
+func TestPlayerFindsChangedDeal(t *testing.T) {
+	dealer, err := NewDealer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dealer.Commitments.Players = [2]string{p.commitment, other.commitment}
+	dealer.SetSecret(sixtysix.Player1, p.secret)
+	dealer.SetSecret(sixtysix.Player2, other.secret)
+	p.commitments = dealer.Commitments
+
+	game := sixtysix.NewWithDeals(func(deal int) rand.Source {
+		source, _ := dealer.Source(deal)
+		return source
+	})
+	game.Start()
+	state, _ := protocol.NewMessage(protocol.State, protocol.StateBody{
+		Hand:  game.Hand(sixtysix.Player1),
+		Trump: game.Trump(),
+		Turn:  game.PlayerInTurn(),
+	})
+	p.observe(state)
+
+	reveal, _ := dealer.Reveal(1)
+	if err := p.check(reveal); err != nil {
+		t.Error("Fair deal error!", err)
+	}
+	if _, err := dealer.Reveal(Deals + 1); err != ErrBadDeal {
+		t.Error("Expected bad deal!", err)
+	}
+
+	changed := reveal
+	changed.Server, _ = DealSecret(dealer.secret, 2)
+	if _, ok := p.check(changed).(*Unfair); !ok {
+		t.Error("Changed secret error!")
+	}
+
+	changed = reveal
+	p.first[0], p.first[1] = p.first[1], game.Trump()
+	if _, ok := p.check(changed).(*Unfair); !ok {
+		t.Error("Changed cards error!")
+	}
+}
+
+func TestSaveDealer(t *testing.T) {
+	dealer, err := NewDealer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for player := range dealer.Commitments.Players {
+		secret, _ := NewSecret()
+		dealer.Commitments.Players[player], _ = Commit(secret)
+		dealer.SetSecret(player, secret)
+	}
+
+	data, err := json.Marshal(dealer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Dealer
+	err = json.Unmarshal(data, &loaded)
+	got, _ := loaded.Reveal(3)
+	want, _ := dealer.Reveal(3)
+	if err != nil || got != want {
+		t.Error("Load error!", err)
+	}
+
+	dealer.secrets[1] = dealer.secret
+	data, _ = json.Marshal(dealer)
+	if err := json.Unmarshal(data, &loaded); err != ErrBadSecret {
+		t.Error("Expected bad secret!", err)
+	}
+}
@@ -0,0 +1,243 @@
+package fair
+
+import (
+	"strconv"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+)
+
+// Unfair is returned when the server hasn't kept to what it has committed to.
+// Deal is 0 if it has happened before the first deal.
+type Unfair struct {
+	Deal   int
+	Reason string
+}
+
+// Error returns the reason with the number of the deal.
+func (u *Unfair) Error() string {
+	if u.Deal == 0 {
+		return "The deals are not fair: " + u.Reason
+	}
+	return "Deal " + strconv.Itoa(u.Deal) + " was not fair: " + u.Reason
+}
+
+// Player takes part in the deals for a client and checks every deal
+// when it is revealed against what the client has seen.
+type Player struct {
+	secret      string
+	commitment  string
+	commitments protocol.CommitmentsBody
+	seat        int
+
+	deal      int                // the number of the current deal
+	newDeal   bool               // the next state is the first of a deal
+	first     []deck.Card        // the cards dealt to the player
+	firstTurn int                // who was in turn when the cards were dealt
+	dealt     deck.Card          // the trump card when the cards were dealt
+	cards     map[deck.Card]bool // every card the player has had in the deal
+	talon     int                // the position in the deck of the next card to be drawn
+	draws     []int              // the positions in the deck of the cards drawn by the player
+	closed    bool
+	exchanged bool // by anyone
+	mine      bool // the player has exchanged
+	resumed   bool // the match was saved with secrets this player doesn't know
+	back      bool // the player has come back in the middle of a deal, which cannot be checked whole
+}
+
+// NewPlayer returns a player with a new secret.
+func NewPlayer() (*Player, error) {
+	secret, err := NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := Commit(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Player{secret: secret, commitment: commitment, newDeal: true}, nil
+}
+
+// Handle answers the messages of the server about the deals on conn and keeps
+// what the player sees. It does nothing if p is nil or the server doesn't speak
+// protocol.FairDeals or if the match is resumed by another client, because the
+// secrets of the match are of the earlier one. If p comes back to the match,
+// only the secrets of the deal it has come back in are checked.
+// It returns *Unfair if the server has cheated.
+func (p *Player) Handle(conn *protocol.Conn, message protocol.Message) error {
+	if p == nil || p.resumed || conn.Version() < protocol.FairDeals {
+		return nil
+	}
+
+	switch message.Type {
+	case protocol.Start:
+		var start protocol.StartBody
+		message.Decode(&start)
+		if !start.Fair {
+			return nil
+		}
+		if start.Resumed {
+			// the server knows the secret of p only if p has sent it
+			p.resumed = p.commitments.Server == ""
+			p.back = !p.resumed
+			return nil
+		}
+		p.seat = start.Seat
+		return conn.Send(protocol.Commit, protocol.CommitBody{Commitment: p.commitment})
+	case protocol.Commitments:
+		message.Decode(&p.commitments)
+		if p.commitments.Players[p.seat] != p.commitment {
+			return &Unfair{Reason: "Your commitment was changed"}
+		}
+		return conn.Send(protocol.Reveal, protocol.RevealBody{Secret: p.secret})
+	case protocol.DealReveal:
+		var reveal protocol.DealRevealBody
+		message.Decode(&reveal)
+		return p.check(reveal)
+	}
+	p.observe(message)
+	return nil
+}
+
+// observe keeps what the player sees in the deal.
+func (p *Player) observe(message protocol.Message) {
+	switch message.Type {
+	case protocol.State:
+		var state protocol.StateBody
+		message.Decode(&state)
+		if p.newDeal {
+			p.startDeal(state)
+		}
+		for _, card := range state.Hand {
+			if card != deck.NoCard {
+				p.cards[card] = true
+			}
+		}
+	case protocol.Closed:
+		p.closed = true
+	case protocol.Exchanged:
+		var who protocol.SeatBody
+		message.Decode(&who)
+		p.exchanged = true
+		p.mine = p.mine || who.Seat == p.seat
+	case protocol.TrickResult:
+		var result protocol.ResultBody
+		message.Decode(&result)
+		if p.closed || p.talon >= deck.Size {
+			break
+		}
+		// the winner draws first and the last card drawn is the trump
+		if result.Winner == p.seat {
+			p.draws = append(p.draws, p.talon)
+		} else {
+			p.draws = append(p.draws, p.talon+1)
+		}
+		p.talon += 2
+	case protocol.DealResult:
+		p.newDeal = true
+	}
+}
+
+// startDeal forgets the last deal and keeps the dealt cards from state.
+func (p *Player) startDeal(state protocol.StateBody) {
+	p.deal++
+	p.newDeal = false
+	p.first = append([]deck.Card(nil), state.Hand...)
+	p.firstTurn = state.Turn
+	p.dealt = state.Trump
+	p.cards = make(map[deck.Card]bool)
+	p.talon = 13
+	p.draws = nil
+	p.closed, p.exchanged, p.mine = false, false, false
+}
+
+// check checks that the revealed deal matches the commitments and what the player has seen.
+func (p *Player) check(reveal protocol.DealRevealBody) error {
+	unfair := func(reason string) error {
+		return &Unfair{Deal: reveal.Deal, Reason: reason}
+	}
+	if p.back {
+		p.deal = reveal.Deal
+	}
+	if reveal.Deal != p.deal || p.cards == nil {
+		return unfair("The number of the deal is wrong")
+	}
+	own, err := DealSecret(p.secret, reveal.Deal)
+	if err != nil || own != reveal.Players[p.seat] {
+		return unfair("Your secret was changed")
+	}
+	if !Check(reveal.Server, p.commitments.Server, reveal.Deal) ||
+		!Check(reveal.Players[0], p.commitments.Players[0], reveal.Deal) ||
+		!Check(reveal.Players[1], p.commitments.Players[1], reveal.Deal) {
+		return unfair("The secrets don't match the commitments")
+	}
+	if p.back {
+		// some of the cards were drawn while the player was away
+		p.back = false
+		return nil
+	}
+
+	cards := Deck(reveal.Server, reveal.Players[0], reveal.Players[1])
+	dealt := append(append([]deck.Card(nil), cards[3:6]...), cards[9:12]...)
+	if p.firstTurn == p.seat {
+		dealt = append(append([]deck.Card(nil), cards[0:3]...), cards[6:9]...)
+	}
+	if !sameCards(p.first, dealt) || p.dealt != cards[12] {
+		return unfair("Your cards were not dealt from the deck")
+	}
+
+	expected := dealt
+	for _, pos := range p.draws {
+		switch {
+		case pos < deck.Size:
+			expected = append(expected, cards[pos])
+		case p.exchanged:
+			expected = append(expected, deck.NewCard(deck.Nine, cards[12].Suit()))
+		default:
+			expected = append(expected, cards[12])
+		}
+	}
+	if p.mine {
+		expected = append(expected, cards[12])
+	}
+	// the nine of trumps is expected twice if the player exchanges it and draws it back
+	want := make(map[deck.Card]bool)
+	for _, card := range expected {
+		want[card] = true
+	}
+	if !sameSet(p.cards, want) {
+		return unfair("Your cards were not drawn from the deck")
+	}
+	return nil
+}
+
+// sameSet returns true if a and b have the same cards.
+func sameSet(a, b map[deck.Card]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for card := range a {
+		if !b[card] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameCards returns true if a and b have the same cards in any order.
+func sameCards(a, b []deck.Card) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[deck.Card]int)
+	for _, card := range a {
+		count[card]++
+	}
+	for _, card := range b {
+		count[card]--
+		if count[card] < 0 {
+			return false
+		}
+	}
+	return true
+}
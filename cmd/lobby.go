@@ -0,0 +1,197 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/DanislavKirov/sixtySix/cmd/clock"
+	"github.com/DanislavKirov/sixtySix/cmd/fair"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// maxTarget is the most points a table can be played to. The secrets of the fair deals
+// are enough for the games played to so many points.
+const maxTarget = fair.MaxTarget
+
+// guest is a player in the lobby. Others can invite him if he has a name.
+type guest struct {
+	*player
+	name string
+}
+
+// table is a match in the lobby waiting for a second player.
+type table struct {
+	protocol.TableBody
+	host    *guest
+	control clock.Control
+	joins   chan *guest // gets the second player, who has already left the lobby
+}
+
+// enter puts p in the lobby under name and serves him there until he sits at a table.
+func (s *server) enter(p *player, name string) {
+	s.mu.Lock()
+	if name != "" && s.guest(name) != nil {
+		s.mu.Unlock()
+		p.proto.SendError(protocol.NameTaken, "There is already a player called "+name)
+		p.close()
+		return
+	}
+	g := &guest{player: p, name: name}
+	s.guests[g] = true
+	g.send(protocol.Tables, s.tableList(name))
+	s.mu.Unlock()
+
+	s.lobby(g)
+}
+
+// guest returns the player in the lobby called name or nil. It must be called with s.mu locked.
+func (s *server) guest(name string) *guest {
+	for g := range s.guests {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// tableList returns the tables the player called name can join. It must be called with s.mu locked.
+func (s *server) tableList(name string) protocol.TablesBody {
+	list := protocol.TablesBody{Tables: []protocol.TableBody{}}
+	for _, t := range s.tables {
+		if t.Invite == "" || t.Invite == name {
+			list.Tables = append(list.Tables, t.TableBody)
+		}
+	}
+	sort.Slice(list.Tables, func(i, j int) bool {
+		return list.Tables[i].Name < list.Tables[j].Name
+	})
+	return list
+}
+
+// lobby serves g until he sits at a table or leaves.
+func (s *server) lobby(g *guest) {
+	var open *table // the table g waits at
+	joins := func() <-chan *guest {
+		if open == nil {
+			return nil
+		}
+		return open.joins
+	}
+
+	for {
+		select {
+		case message, ok := <-g.inputs:
+			if !ok || message.Type == protocol.Quit {
+				s.leave(g, open)
+				return
+			}
+			switch {
+			case message.Type == protocol.List:
+				s.mu.Lock()
+				g.send(protocol.Tables, s.tableList(g.name))
+				s.mu.Unlock()
+			case open != nil && (message.Type == protocol.Create || message.Type == protocol.Join):
+				g.proto.SendError(protocol.NotPossible, "You are already waiting at table "+open.Name)
+			case message.Type == protocol.Create:
+				open = s.create(g, message)
+			case message.Type == protocol.Join:
+				if s.join(g, message) {
+					return
+				}
+			default:
+				g.proto.SendError(protocol.NotPossible, "Create or join a table first")
+			}
+		case second := <-joins():
+			s.mu.Lock()
+			delete(s.guests, g)
+			s.pair(g.player, second.player, open.Target, open.control)
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// create opens the table g has asked for in message and tells the invited player
+// about it. It returns nil if the table cannot be opened.
+func (s *server) create(g *guest, message protocol.Message) *table {
+	var body protocol.TableBody
+	if err := message.Decode(&body); err != nil || body.Name == "" {
+		g.proto.SendError(protocol.BadMessage, "Expected a table with a name")
+		return nil
+	}
+	if body.Target == 0 {
+		body.Target = sixtysix.WinningPoints
+	}
+	if body.Target < 1 || body.Target > maxTarget {
+		g.proto.SendError(protocol.BadMessage, "The game can be played to 1-"+strconv.Itoa(maxTarget)+" points")
+		return nil
+	}
+	t := &table{host: g, control: s.control, joins: make(chan *guest, 1)}
+	if body.Clock != "" {
+		control, err := clock.ParseControl(body.Clock)
+		if err != nil {
+			g.proto.SendError(protocol.BadMessage, err.Error())
+			return nil
+		}
+		t.control = control
+	}
+	body.Host, body.Clock = g.name, ""
+	if t.control.IsSet() {
+		body.Clock = t.control.String()
+	}
+	t.TableBody = body
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tables[t.Name] != nil {
+		g.proto.SendError(protocol.NameTaken, "There is already a table called "+t.Name)
+		return nil
+	}
+	s.tables[t.Name] = t
+	g.send(protocol.Waiting, nil)
+	if invited := s.guest(t.Invite); t.Invite != "" && invited != nil {
+		invited.send(protocol.Invited, t.TableBody)
+	}
+	return t
+}
+
+// join sits g at the table he has asked for in message. It returns false if he cannot sit there.
+func (s *server) join(g *guest, message protocol.Message) bool {
+	var body protocol.JoinBody
+	message.Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tables[body.Name]
+	if t == nil || t.Invite != "" && t.Invite != g.name || t.host.isGone() {
+		g.proto.SendError(protocol.UnknownTable, "There is no open table called "+body.Name)
+		return false
+	}
+	delete(s.tables, t.Name)
+	delete(s.guests, g)
+	t.joins <- g
+	return true
+}
+
+// leave takes g and his table out of the lobby. If a player has just sat
+// at the table, he goes back to the lobby.
+func (s *server) leave(g *guest, open *table) {
+	g.close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.guests, g)
+	if open == nil {
+		return
+	}
+	if s.tables[open.Name] == open {
+		delete(s.tables, open.Name)
+		return
+	}
+
+	second := <-open.joins
+	second.proto.SendError(protocol.UnknownTable, "The host of "+open.Name+" has left")
+	s.guests[second] = true
+	go s.lobby(second)
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/clock"
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/fair"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// match is a game between two connected players.
+// If fair is true, the players take part in the deals as described in package fair.
+// A player who has lost the connection keeps his seat for grace and can come
+// back to it with his token. If file isn't empty, the match is also saved there
+// after every trick, so that the players can come back after the server restarts.
+// If clock isn't nil, the player in turn is warned and then given the penalty
+// of the time control when his time runs out. The spectators see the game
+// as described in package protocol.
+type match struct {
+	id      string
+	game    *sixtysix.Game
+	players [2]*player
+	fair    bool
+	dealer  *fair.Dealer
+	deal    int // the number of the current deal
+	rated   bool
+	hints   bool // the players can ask for the best play, only against a bot
+	tokens  [2]string
+	file    string
+	resumed bool // the match goes on from a saved one
+	over    bool // the game has ended or a player has quit
+	grace   time.Duration
+	timeout time.Duration // how long a player has to send his part of the deals
+	clock   *clock.Clock
+	warned  bool // the player in turn has been warned that his time runs out
+
+	spectators []*spectator
+	limits     [2]limiter // how much each player can say
+	muted      [2]bool    // the players who don't want to get what their opponents say
+
+	rejoins  chan *player    // the players who come back
+	watchers chan *spectator // the spectators who join
+	done     chan struct{}   // closed when the match has ended
+	stopped  chan struct{}   // closed when the match must end
+	stopOnce sync.Once
+}
+
+// newMatch creates a match of game between the two players.
+func newMatch(game *sixtysix.Game, player1, player2 *player) *match {
+	return &match{
+		game:     game,
+		players:  [2]*player{player1, player2},
+		rejoins:  make(chan *player),
+		watchers: make(chan *spectator),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// start returns the start of player who has come back if resumed is true.
+func (m *match) start(player int, resumed bool) protocol.StartBody {
+	return protocol.StartBody{Seat: player, Token: m.tokens[player], Resumed: resumed, Saved: m.file != "", Match: m.id, Target: m.game.Target(), Fair: m.fair || m.dealer != nil}
+}
+
+// state returns what player can see now.
+func (m *match) state(player int) protocol.StateBody {
+	deckSize := m.game.TalonSize()
+	if deckSize != 0 {
+		deckSize++ // counting the trump
+	}
+
+	return protocol.StateBody{
+		Hand:      m.game.Hand(player),
+		Trump:     m.game.Trump(),
+		DeckSize:  deckSize,
+		Closed:    m.game.IsClosed(),
+		Table:     m.game.Table(sixtysix.OpponentOf(player)),
+		Score:     m.game.DealScore(player),
+		GameScore: [2]int{m.game.GameScore(sixtysix.Player1), m.game.GameScore(sixtysix.Player2)},
+		Turn:      m.game.PlayerInTurn(),
+		Clock:     m.clockBody(),
+	}
+}
+
+// sendState sends to each player what he can see now.
+func (m *match) sendState() {
+	m.sendTo(sixtysix.Player1, protocol.State, m.state(sixtysix.Player1))
+	m.sendTo(sixtysix.Player2, protocol.State, m.state(sixtysix.Player2))
+	m.showView()
+}
+
+// sendTo sends a message with type t and the given body to player.
+func (m *match) sendTo(player int, t protocol.Type, body interface{}) {
+	m.players[player].send(t, body)
+}
+
+// sendAll sends a message with type t and the given body to both players
+// and to the spectators unless it is about the fair deals.
+func (m *match) sendAll(t protocol.Type, body interface{}) {
+	m.sendTo(sixtysix.Player1, t, body)
+	m.sendTo(sixtysix.Player2, t, body)
+	if t != protocol.Commitments && t != protocol.DealReveal && t != protocol.Error {
+		m.show(t, body)
+	}
+}
+
+// sendError sends an error with the given code to player.
+func (m *match) sendError(player int, code protocol.ErrorCode, message string) {
+	m.players[player].proto.SendError(code, message)
+}
+
+// sendDealResult informs the players who won the deal and how many points.
+// It returns false if the game is over or if the deals are fair and the secrets
+// aren't enough for the next one, which nobody could check.
+func (m *match) sendDealResult(winner, pts int) bool {
+	score := [2]int{m.game.GameScore(sixtysix.Player1), m.game.GameScore(sixtysix.Player2)}
+	m.sendAll(protocol.DealResult, protocol.ResultBody{Winner: winner, Points: pts, Score: &score})
+	m.releaseDeal()
+	if m.dealer != nil {
+		if reveal, err := m.dealer.Reveal(m.deal); err == nil {
+			m.sendAll(protocol.DealReveal, reveal)
+		}
+		m.deal++
+		if m.deal > fair.Deals && !m.game.IsOver() {
+			m.sendAll(protocol.Error, protocol.ErrorBody{Code: protocol.NotPossible, Message: "The secrets aren't enough for more deals"})
+			return false
+		}
+	}
+
+	if m.game.IsOver() {
+		m.sendGameResult()
+		return false
+	}
+	return true
+}
+
+// sendGameResult informs the players who won the game.
+func (m *match) sendGameResult() {
+	score := [2]int{m.game.GameScore(sixtysix.Player1), m.game.GameScore(sixtysix.Player2)}
+	m.sendAll(protocol.GameResult, protocol.ResultBody{Winner: m.game.Winner(), Points: score[m.game.Winner()], Score: &score})
+}
+
+// run starts the game and handles what the players send until the match is over.
+func (m *match) run() {
+	defer m.close()
+	defer m.endShow()
+
+	for _, player := range [2]int{sixtysix.Player1, sixtysix.Player2} {
+		m.sendTo(player, protocol.Start, m.start(player, m.resumed))
+	}
+	if !m.resumed {
+		if m.fair && !m.agree() {
+			return
+		}
+		m.game.Start()
+		m.save()
+	}
+	m.startMove()
+	m.sendState()
+
+	// when the players who have lost the connection lose their seats
+	var away [2]<-chan time.Time
+	inputs := func(player int) <-chan protocol.Message {
+		if away[player] != nil {
+			return nil
+		}
+		return m.players[player].inputs
+	}
+	health := func(player int) <-chan bool {
+		if away[player] != nil {
+			return nil
+		}
+		return m.players[player].health
+	}
+	for {
+		var (
+			player  int
+			message protocol.Message
+			ok      bool
+		)
+		select {
+		case <-m.alarm():
+			if !m.tick() {
+				m.over = true
+				m.forget()
+				return
+			}
+			continue
+		case message, ok = <-inputs(sixtysix.Player1):
+			player = sixtysix.Player1
+		case message, ok = <-inputs(sixtysix.Player2):
+			player = sixtysix.Player2
+		case fine := <-health(sixtysix.Player1):
+			m.sendHealth(sixtysix.Player1, fine)
+			continue
+		case fine := <-health(sixtysix.Player2):
+			m.sendHealth(sixtysix.Player2, fine)
+			continue
+		case w := <-m.watchers:
+			m.welcome(w)
+			continue
+		case p := <-m.rejoins:
+			player = m.seatOf(p)
+			if away[player] != nil {
+				away[player] = nil
+				m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentBack, nil)
+			}
+			m.welcomeBack(player, p)
+			continue
+		case <-away[sixtysix.Player1]:
+			m.sendTo(sixtysix.Player2, protocol.OpponentLeft, nil)
+			return
+		case <-away[sixtysix.Player2]:
+			m.sendTo(sixtysix.Player1, protocol.OpponentLeft, nil)
+			return
+		case <-m.stopped:
+			return
+		}
+
+		if !ok {
+			if m.grace > 0 {
+				away[player] = time.After(m.grace)
+				m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentAway, nil)
+				continue
+			}
+			// the match stays saved, so the players can come back to it
+			m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentLeft, nil)
+			return
+		}
+		if !m.handle(player, message) {
+			m.over = true
+			m.forget()
+			return
+		}
+	}
+}
+
+// agree collects the commitments and then the secrets of the players and
+// creates a game whose deals are decided by them. It returns false if a player
+// has left or hasn't sent his part in time.
+func (m *match) agree() bool {
+	dealer, err := fair.NewDealer()
+	if err != nil {
+		m.sendAll(protocol.Error, protocol.ErrorBody{Code: protocol.NotPossible, Message: err.Error()})
+		return false
+	}
+
+	for _, player := range [2]int{sixtysix.Player1, sixtysix.Player2} {
+		var commit protocol.CommitBody
+		if !m.receive(player, protocol.Commit, &commit) {
+			return false
+		}
+		dealer.Commitments.Players[player] = commit.Commitment
+	}
+	m.sendAll(protocol.Commitments, dealer.Commitments)
+
+	for _, player := range [2]int{sixtysix.Player1, sixtysix.Player2} {
+		var reveal protocol.RevealBody
+		if !m.receive(player, protocol.Reveal, &reveal) {
+			return false
+		}
+		if err := dealer.SetSecret(player, reveal.Secret); err != nil {
+			m.sendError(player, protocol.BadMessage, "The secret doesn't match the commitment")
+			m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentLeft, nil)
+			return false
+		}
+	}
+
+	target := m.game.Target()
+	m.dealer, m.deal = dealer, 1
+	m.game = sixtysix.NewWithDeals(dealSources(dealer))
+	m.game.SetTarget(target)
+	return true
+}
+
+// dealSources returns the sources which shuffle the decks of the deals of dealer.
+// The deck of a deal the secrets aren't enough for is never played,
+// because the match ends before it, see sendDealResult.
+func dealSources(dealer *fair.Dealer) func(deal int) rand.Source {
+	return func(deal int) rand.Source {
+		source, err := dealer.Source(deal)
+		if err != nil {
+			return rand.NewSource(int64(deal))
+		}
+		return source
+	}
+}
+
+// receive waits for a message with type t from player and decodes its body into v.
+// It returns false if the player has left or hasn't sent it in m.timeout,
+// which is the same as leaving.
+func (m *match) receive(player int, t protocol.Type, v interface{}) bool {
+	timeout := time.NewTimer(m.timeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case message, ok := <-m.players[player].inputs:
+			if !ok || message.Type == protocol.Quit {
+				m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentLeft, nil)
+				return false
+			}
+			if m.chat(player, message) {
+				continue
+			}
+			if message.Type == t && message.Decode(v) == nil {
+				return true
+			}
+			m.sendError(player, protocol.BadMessage, "Expected "+t.String())
+		case <-timeout.C:
+			m.sendError(player, protocol.NotPossible, "Too late for "+t.String())
+			m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentLeft, nil)
+			return false
+		}
+	}
+}
+
+// close closes the connections of both players.
+func (m *match) close() {
+	m.players[sixtysix.Player1].close()
+	m.players[sixtysix.Player2].close()
+}
+
+// stop ends the match even if a player has lost the connection and may come back.
+func (m *match) stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopped)
+	})
+	m.close()
+}
+
+// rejoin gives p the seat of his token. It returns false if the match has ended.
+func (m *match) rejoin(p *player) bool {
+	select {
+	case m.rejoins <- p:
+		return true
+	case <-m.done:
+		return false
+	}
+}
+
+// seatOf returns the seat of the player with the token of p.
+func (m *match) seatOf(p *player) int {
+	if m.tokens[sixtysix.Player2] == p.proto.Token() {
+		return sixtysix.Player2
+	}
+	return sixtysix.Player1
+}
+
+// sendHealth tells the opponent of player whether the connection of player is fine.
+func (m *match) sendHealth(player int, fine bool) {
+	t := protocol.OpponentUnstable
+	if fine {
+		t = protocol.OpponentBack
+	}
+	m.sendTo(sixtysix.OpponentOf(player), t, nil)
+}
+
+// welcomeBack gives the seat of player to p and sends him everything he can see.
+func (m *match) welcomeBack(player int, p *player) {
+	if m.players[player] != p {
+		m.players[player].close() // the server hasn't noticed that the old connection is lost
+		m.players[player] = p
+	}
+	m.sendTo(player, protocol.Start, m.start(player, true))
+	m.sendTo(player, protocol.State, m.state(player))
+}
+
+// handle responds to what player sent. It returns false if the match is over.
+func (m *match) handle(player int, message protocol.Message) bool {
+	switch message.Type {
+	case protocol.Play:
+		var play protocol.PlayBody
+		if err := message.Decode(&play); err != nil {
+			m.sendError(player, protocol.BadMessage, err.Error())
+			break
+		}
+		return m.play(player, play.Card)
+	case protocol.Close:
+		if m.game.Close(player) {
+			m.save()
+			m.sendAll(protocol.Closed, protocol.SeatBody{Seat: player})
+			m.sendState()
+		} else {
+			m.sendError(player, protocol.NotPossible, "The deck cannot be closed now")
+		}
+	case protocol.Exchange:
+		if m.game.Exchange(player) {
+			m.save()
+			m.sendAll(protocol.Exchanged, protocol.SeatBody{Seat: player})
+			m.sendState()
+		} else {
+			m.sendError(player, protocol.NotPossible, "The trump cannot be exchanged now")
+		}
+	case protocol.Stop:
+		success, winner, pts := m.game.Stop(player)
+		if !success {
+			m.sendError(player, protocol.NotPossible, "The deal cannot be stopped now")
+			break
+		}
+		m.save()
+		if !m.sendDealResult(winner, pts) {
+			return false
+		}
+		m.startMove()
+		m.sendState()
+	case protocol.Hint:
+		m.hint(player)
+	case protocol.Say, protocol.Mute:
+		m.chat(player, message)
+	case protocol.Commit:
+		// the clients which don't know StartBody.Fair commit in every match
+	case protocol.Quit:
+		m.sendTo(sixtysix.OpponentOf(player), protocol.OpponentLeft, nil)
+		return false
+	default:
+		m.sendError(player, protocol.BadMessage, "Unexpected message: "+message.Type.String())
+	}
+	return true
+}
+
+// errorCodes maps the errors of the game to the codes sent to the players.
+var errorCodes = map[error]protocol.ErrorCode{
+	sixtysix.ErrNotYourTurn: protocol.NotYourTurn,
+	sixtysix.ErrInvalidCard: protocol.InvalidCard,
+	sixtysix.ErrGameOver:    protocol.GameOver,
+}
+
+// play plays card from the hand of player. It returns false if the match is over.
+func (m *match) play(player int, card deck.Card) bool {
+	cardIdx := -1
+	for idx, c := range m.game.Hand(player) {
+		if c == card && c != sixtysix.NoCard {
+			cardIdx = idx
+		}
+	}
+
+	move, err := m.game.Play(player, cardIdx)
+	if err != nil {
+		m.sendError(player, errorCodes[err], err.Error())
+		return true
+	}
+
+	m.sendAll(protocol.Played, protocol.PlayedBody{Seat: player, Card: move.Card, Marriage: move.Marriage})
+	if move.TrickWinner != sixtysix.Nobody {
+		m.save()
+		m.sendAll(protocol.TrickResult, protocol.ResultBody{Winner: move.TrickWinner, Points: move.TrickPoints})
+	}
+	if move.DealWinner != sixtysix.Nobody && !m.sendDealResult(move.DealWinner, move.DealPoints) {
+		return false
+	}
+	m.startMove()
+	m.sendState()
+	return true
+}
+
+// startMove starts the clock of the player in turn after a card is played or a deal begins.
+func (m *match) startMove() {
+	if m.clock != nil && !m.game.IsOver() {
+		m.clock.Start(m.game.PlayerInTurn(), time.Now())
+		m.warned = false
+	}
+}
+
+// milliseconds returns d in milliseconds or -1 if d is negative.
+func milliseconds(d time.Duration) int64 {
+	if d < 0 {
+		return -1
+	}
+	return int64(d / time.Millisecond)
+}
+
+// clockBody returns the time left or nil if the time isn't limited.
+func (m *match) clockBody() *protocol.ClockBody {
+	if m.clock == nil {
+		return nil
+	}
+	move, game := m.clock.Left(time.Now())
+	return &protocol.ClockBody{Move: milliseconds(move), Game: [2]int64{milliseconds(game[0]), milliseconds(game[1])}}
+}
+
+// alarm returns a channel which receives when the player in turn must be warned
+// or given the penalty or nil if the time isn't limited.
+func (m *match) alarm() <-chan time.Time {
+	if m.clock == nil {
+		return nil
+	}
+	at, ok := m.clock.WarningAt()
+	if m.warned {
+		at, ok = m.clock.Deadline()
+	}
+	if !ok {
+		return nil
+	}
+	return time.After(time.Until(at))
+}
+
+// tick warns the player in turn or gives him the penalty if his time has run out.
+// It returns false if the match is over.
+func (m *match) tick() bool {
+	deadline, ok := m.clock.Deadline()
+	if !ok {
+		return true
+	}
+	if time.Now().Before(deadline) {
+		if !m.warned {
+			m.warned = true
+			m.sendTo(m.clock.Running(), protocol.TimeWarning, m.clockBody())
+		}
+		return true
+	}
+	return m.timeOut(m.clock.Running())
+}
+
+// timeOut gives the penalty of the time control to player. It returns false if the match is over.
+func (m *match) timeOut(player int) bool {
+	penalty := m.clock.Control.Penalty
+	m.sendAll(protocol.TimeOut, protocol.TimeOutBody{Seat: player, Penalty: penalty.String()})
+
+	switch penalty {
+	case clock.AutoPlay:
+		idx := m.game.LowestCard(player)
+		if idx < 0 {
+			m.startMove()
+			return true
+		}
+		return m.play(player, m.game.Hand(player)[idx])
+	case clock.ForfeitDeal:
+		winner, pts := m.game.ForfeitDeal(player)
+		m.save()
+		if !m.sendDealResult(winner, pts) {
+			return false
+		}
+		m.startMove()
+		m.sendState()
+		return true
+	}
+	m.game.ForfeitGame(player)
+	m.sendGameResult()
+	return false
+}
+
+// hint sends to player the best play if the deck is empty and it's his turn.
+func (m *match) hint(player int) {
+	if !m.hints {
+		m.sendError(player, protocol.NotPossible, "Hints are given only in the games against a bot")
+		return
+	}
+	if m.game.TalonSize() != 0 || m.game.PlayerInTurn() != player {
+		m.sendError(player, protocol.NotPossible, "A hint is given only on your turn after the deck is empty")
+		return
+	}
+
+	solution, err := m.game.Solve()
+	if err != nil {
+		m.sendError(player, protocol.NotPossible, err.Error())
+		return
+	}
+	m.sendTo(player, protocol.Solution, protocol.SolutionBody{
+		Card:   solution.Card,
+		Stop:   solution.Stop,
+		Winner: solution.Winner,
+		Points: solution.Points,
+		Score:  solution.Score,
+	})
+}
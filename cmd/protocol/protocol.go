@@ -0,0 +1,862 @@
+// Package protocol provides the messages sent between the clients and the server
+// and the framing which keeps them apart on the wire.
+//
+// Every message is sent as a frame: four bytes big-endian length of the rest,
+// one byte for the type of the message and then the body, which is JSON.
+// The bodies of the different types are described by the *Body structs.
+//
+// A client starts with Hello listing the versions it speaks. The server answers
+// with Welcome and the version it picked or with Error and closes the connection.
+// Neither side sends the other the types of messages added in a newer version.
+//
+// Since version 2 (FairDeals) the players can check that the deals are fair
+// as described in package fair: after Start with Fair each player sends Commit, the server
+// answers with Commitments, each player sends Reveal and then the game starts.
+// After every DealResult the server sends DealReveal. A player who doesn't send
+// Commit or Reveal in time has left the match. If the secrets aren't enough for
+// the next deal, the match ends with Error instead of a deal nobody can check.
+//
+// Hello can also ask for a notation from deck.Notations by name. If the server
+// knows it, Welcome repeats it and from then on the cards in both directions of
+// that connection are written in it instead of like X♠. Either side reads the
+// cards in any of the notations.
+//
+// The server sends each player a token in Start. If the connection of a player
+// is lost, the server keeps the seat for a while. The player can come back with
+// the token in Hello: the server sends him Start with Resumed and State. Since
+// version 3 (Reconnects) the other player gets OpponentAway and then OpponentBack meanwhile.
+// A server which saves its matches also lets the players come back to a saved
+// match with their tokens after it restarts, when both of them are back.
+//
+// If the time is limited, State has the clocks of the players. Since version 4
+// (Clocks) the server sends TimeWarning to the player in turn shortly before his
+// time runs out and then TimeOut to both players with the penalty it gives him.
+//
+// Since version 5 (Heartbeats) the server sends Ping at least every
+// HeartbeatInterval and the client answers with Pong. If the server hears
+// nothing from a player for a while, it sends OpponentUnstable to the other one
+// and OpponentBack when the player answers again, or it drops the connection.
+// A client which hears nothing from the server for HeartbeatTimeout drops it too.
+//
+// Start tells the players the id of their match. Since version 6 (Spectators)
+// a client can watch it by sending the id in Hello instead of playing. The
+// server sends the spectator Start without a seat, View instead of State and
+// the messages about the game both players get. A commentator sees the hands
+// in View too, but everything about a deal reaches him when the deal is over.
+// He must send in Hello the password the host of the server has given to the
+// commentators, otherwise he gets Error with NotPossible.
+// A spectator can only send Quit.
+//
+// Since version 7 (Lobbies) a client can ask in Hello to go to the lobby
+// instead of being paired with the next player. The server sends him Tables,
+// the tables waiting for a second player, and again after each List. He can
+// Create a table, which the server answers with Waiting, or Join one, and the
+// match starts as usual when the second player sits at the table. A table can
+// be only for an invited player, who gets Invited if he is in the lobby.
+//
+// Since version 8 (Chat) a player can Say a text or one of Emotes during a
+// match. The server sends Said to his opponent, unless the opponent has sent Mute, and to the spectators.
+// A player who says too much too fast gets Error with RateLimited instead.
+package protocol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// Version is the newest version of the protocol.
+const Version = 8
+
+// FairDeals is the first version in which the players take part in the deals.
+const FairDeals = 2
+
+// Reconnects is the first version in which the server tells a player that
+// his opponent is away.
+const Reconnects = 3
+
+// Clocks is the first version in which the server tells the players
+// that the time runs out.
+const Clocks = 4
+
+// Heartbeats is the first version in which the server pings the clients.
+const Heartbeats = 5
+
+// Spectators is the first version in which a client can watch a match.
+const Spectators = 6
+
+// Lobbies is the first version in which the server has a lobby.
+const Lobbies = 7
+
+// Chat is the first version in which the players can talk.
+const Chat = 8
+
+// Versions are all versions of the protocol this package speaks.
+var Versions = []int{1, FairDeals, Reconnects, Clocks, Heartbeats, Spectators, Lobbies, Chat}
+
+// since is the first version which has each type added after version 1.
+// Conn.Send doesn't send a type to a peer which speaks an older version.
+// Hint and Solution aren't here, because Solution only answers Hint.
+var since = map[Type]int{
+	Commit:           FairDeals,
+	Commitments:      FairDeals,
+	Reveal:           FairDeals,
+	DealReveal:       FairDeals,
+	OpponentAway:     Reconnects,
+	OpponentBack:     Reconnects,
+	TimeWarning:      Clocks,
+	TimeOut:          Clocks,
+	Ping:             Heartbeats,
+	Pong:             Heartbeats,
+	OpponentUnstable: Heartbeats,
+	View:             Spectators,
+	List:             Lobbies,
+	Tables:           Lobbies,
+	Create:           Lobbies,
+	Join:             Lobbies,
+	Invited:          Lobbies,
+	Say:              Chat,
+	Said:             Chat,
+	Mute:             Chat,
+}
+
+// HeartbeatInterval is the longest time between two Pings of the server.
+const HeartbeatInterval = 5 * time.Second
+
+// HeartbeatTimeout is how long a client waits for the server before the connection is lost.
+const HeartbeatTimeout = 4 * HeartbeatInterval
+
+// Negotiate returns the newest version from versions this package speaks.
+func Negotiate(versions []int) (int, bool) {
+	best := 0
+	for _, v := range versions {
+		for _, supported := range Versions {
+			if v == supported && v > best {
+				best = v
+			}
+		}
+	}
+	return best, best != 0
+}
+
+// Type is the kind of a message.
+type Type byte
+
+// Message types.
+const (
+	// client -> server
+
+	Hello    Type = iota + 1 // HelloBody
+	Play                     // PlayBody
+	Exchange                 // no body
+	Close                    // no body
+	Stop                     // no body
+	Quit                     // no body
+
+	// server -> client
+
+	Welcome      // WelcomeBody
+	Waiting      // no body
+	Start        // StartBody
+	State        // StateBody
+	Played       // PlayedBody
+	Closed       // SeatBody
+	Exchanged    // SeatBody
+	TrickResult  // ResultBody
+	DealResult   // ResultBody
+	GameResult   // ResultBody
+	OpponentLeft // no body
+	Error        // ErrorBody
+
+	// added later, kept at the end so the numbers above don't change
+
+	Hint     // client -> server, no body
+	Solution // server -> client, SolutionBody
+
+	Commit      // client -> server, CommitBody
+	Commitments // server -> client, CommitmentsBody
+	Reveal      // client -> server, RevealBody
+	DealReveal  // server -> client, DealRevealBody
+
+	OpponentAway // server -> client, no body
+	OpponentBack // server -> client, no body
+
+	TimeWarning // server -> client, ClockBody
+	TimeOut     // server -> client, TimeOutBody
+
+	Ping             // server -> client, no body
+	Pong             // client -> server, no body
+	OpponentUnstable // server -> client, no body
+
+	View // server -> spectator, ViewBody
+
+	List    // client -> server, no body
+	Tables  // server -> client, TablesBody
+	Create  // client -> server, TableBody
+	Join    // client -> server, JoinBody
+	Invited // server -> client, TableBody
+
+	Say  // client -> server, SayBody
+	Said // server -> client, SaidBody
+	Mute // client -> server, MuteBody
+)
+
+var typeNames = map[Type]string{
+	Hello:            "hello",
+	Play:             "play",
+	Exchange:         "exchange",
+	Close:            "close",
+	Stop:             "stop",
+	Quit:             "quit",
+	Welcome:          "welcome",
+	Waiting:          "waiting",
+	Start:            "start",
+	State:            "state",
+	Played:           "played",
+	Closed:           "closed",
+	Exchanged:        "exchanged",
+	TrickResult:      "trick-result",
+	DealResult:       "deal-result",
+	GameResult:       "game-result",
+	OpponentLeft:     "opponent-left",
+	Error:            "error",
+	Hint:             "hint",
+	Solution:         "solution",
+	Commit:           "commit",
+	Commitments:      "commitments",
+	Reveal:           "reveal",
+	DealReveal:       "deal-reveal",
+	OpponentAway:     "opponent-away",
+	OpponentBack:     "opponent-back",
+	TimeWarning:      "time-warning",
+	TimeOut:          "time-out",
+	Ping:             "ping",
+	Pong:             "pong",
+	OpponentUnstable: "opponent-unstable",
+	View:             "view",
+	List:             "list",
+	Tables:           "tables",
+	Create:           "create",
+	Join:             "join",
+	Invited:          "invited",
+	Say:              "say",
+	Said:             "said",
+	Mute:             "mute",
+}
+
+// String returns the name of the type.
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return "type(" + strconv.Itoa(int(t)) + ")"
+}
+
+// HelloBody lists the versions of the protocol the client speaks
+// and the notation of the cards it wants, if any. Token is given only
+// by a player who comes back to a saved match.
+type HelloBody struct {
+	Versions []int  `json:"versions"`
+	Notation string `json:"notation,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	// the id of the match the client wants to watch and whether he sees the hands later
+	// with the password the host has given to the commentators
+	Watch       string `json:"watch,omitempty"`
+	Commentator bool   `json:"commentator,omitempty"`
+	Password    string `json:"password,omitempty"`
+
+	// the name of the player, which others invite him with, and whether he goes to the lobby
+	Name  string `json:"name,omitempty"`
+	Lobby bool   `json:"lobby,omitempty"`
+}
+
+// WelcomeBody contains the version of the protocol picked by the server
+// and the notation of the cards if the client has asked for one.
+type WelcomeBody struct {
+	Version  int    `json:"version"`
+	Notation string `json:"notation,omitempty"`
+}
+
+// StartBody tells the player which seat is his. Token lets him come back
+// to the seat and Resumed is true if he has come back. Saved is true if
+// the match is saved, so he can come back even after the server restarts.
+// Fair is true if the players take part in the deals, which they don't
+// if the server repeats the deals from a seed or a player speaks an older version.
+type StartBody struct {
+	Seat    int    `json:"seat"`
+	Token   string `json:"token,omitempty"`
+	Resumed bool   `json:"resumed,omitempty"`
+	Saved   bool   `json:"saved,omitempty"`
+	Match   string `json:"match,omitempty"`
+	Target  int    `json:"target,omitempty"` // the points which win the game
+	Fair    bool   `json:"fair,omitempty"`
+}
+
+// StateBody is what the player can see after something has changed.
+// The cards are written like X♠ and an empty string is deck.NoCard.
+// Table is the card the opponent has put on the table or empty.
+// DeckSize counts the trump too. Clock is sent only if the time is limited.
+type StateBody struct {
+	Hand      []deck.Card `json:"hand"`
+	Trump     deck.Card   `json:"trump"`
+	DeckSize  int         `json:"deckSize"`
+	Closed    bool        `json:"closed"`
+	Table     deck.Card   `json:"table"`
+	Score     int         `json:"score"`
+	GameScore [2]int      `json:"gameScore"`
+	Turn      int         `json:"turn"`
+	Clock     *ClockBody  `json:"clock,omitempty"`
+}
+
+// TableBody is a table in the lobby. Target is the points which win the game
+// and Clock its time control like "30s/10m/play", both the defaults of the
+// server if they are empty. Only Invite can join the table if it isn't empty.
+// The server fills in Host, Target and Clock.
+type TableBody struct {
+	Name   string `json:"name"`
+	Host   string `json:"host,omitempty"`
+	Target int    `json:"target,omitempty"`
+	Clock  string `json:"clock,omitempty"`
+	Invite string `json:"invite,omitempty"`
+}
+
+// TablesBody is the tables waiting for a second player.
+type TablesBody struct {
+	Tables []TableBody `json:"tables"`
+}
+
+// JoinBody is the name of the table the player wants to sit at.
+type JoinBody struct {
+	Name string `json:"name"`
+}
+
+// MaxSay is the most characters a player can say at once.
+const MaxSay = 200
+
+// Emotes are the names of the canned messages a player can say.
+var Emotes = []string{"hi", "gg", "wp", "nice", "oops", "thanks"}
+
+// SayBody is a text or the name of an emote a player says to the others.
+type SayBody struct {
+	Text  string `json:"text,omitempty"`
+	Emote string `json:"emote,omitempty"`
+}
+
+// SaidBody is what the player on Seat has said.
+type SaidBody struct {
+	Seat  int    `json:"seat"`
+	Text  string `json:"text,omitempty"`
+	Emote string `json:"emote,omitempty"`
+}
+
+// MuteBody tells whether the player doesn't want to get Said anymore.
+type MuteBody struct {
+	Muted bool `json:"muted"`
+}
+
+// ViewBody is what a spectator sees of the game. Table has the card of each player
+// in the current trick and Score the points of each player in the deal.
+// Hands are only sent to a commentator.
+type ViewBody struct {
+	Hands     [][]deck.Card `json:"hands,omitempty"`
+	Trump     deck.Card     `json:"trump"`
+	DeckSize  int           `json:"deckSize"`
+	Closed    bool          `json:"closed"`
+	Table     [2]deck.Card  `json:"table"`
+	Score     [2]int        `json:"score"`
+	GameScore [2]int        `json:"gameScore"`
+	Turn      int           `json:"turn"`
+	Clock     *ClockBody    `json:"clock,omitempty"`
+}
+
+// ClockBody is the time left in milliseconds of the move of the player in turn
+// and of the game of each player. The times which aren't limited are -1.
+type ClockBody struct {
+	Move int64    `json:"move"`
+	Game [2]int64 `json:"game"`
+}
+
+// TimeOutBody tells whose time has run out and his penalty:
+// "play" (his lowest card is played), "deal" or "game" (he loses it).
+type TimeOutBody struct {
+	Seat    int    `json:"seat"`
+	Penalty string `json:"penalty"`
+}
+
+// PlayBody is the card the player wants to play.
+type PlayBody struct {
+	Card deck.Card `json:"card"`
+}
+
+// PlayedBody is the card played from seat and the marriage announced with it.
+type PlayedBody struct {
+	Seat     int       `json:"seat"`
+	Card     deck.Card `json:"card"`
+	Marriage int       `json:"marriage,omitempty"`
+}
+
+// SeatBody tells who did something.
+type SeatBody struct {
+	Seat int `json:"seat"`
+}
+
+// ResultBody is the winner of a trick, deal or the game and the points he has won.
+// Score is the game score after a deal or the game.
+type ResultBody struct {
+	Winner int     `json:"winner"`
+	Points int     `json:"points"`
+	Score  *[2]int `json:"score,omitempty"`
+}
+
+// SolutionBody is the best play after the deck is empty and the result of the deal
+// if both players play perfectly. Card is empty if the best is to stop the deal.
+type SolutionBody struct {
+	Card   deck.Card `json:"card,omitempty"`
+	Stop   bool      `json:"stop,omitempty"`
+	Winner int       `json:"winner"`
+	Points int       `json:"points"`
+	Score  [2]int    `json:"score"`
+}
+
+// CommitBody is the commitment of the player to his secret.
+type CommitBody struct {
+	Commitment string `json:"commitment"`
+}
+
+// CommitmentsBody are the commitments of the server and of both players.
+type CommitmentsBody struct {
+	Server  string    `json:"server"`
+	Players [2]string `json:"players"`
+}
+
+// RevealBody is the secret of the player.
+type RevealBody struct {
+	Secret string `json:"secret"`
+}
+
+// DealRevealBody are the secrets of the server and of both players for the deal
+// with number Deal, starting from 1.
+type DealRevealBody struct {
+	Deal    int       `json:"deal"`
+	Server  string    `json:"server"`
+	Players [2]string `json:"players"`
+}
+
+// ErrorCode tells the client what went wrong.
+type ErrorCode string
+
+// Error codes.
+const (
+	UnsupportedVersion ErrorCode = "unsupported-version"
+	BadMessage         ErrorCode = "bad-message"
+	NotYourTurn        ErrorCode = "not-your-turn"
+	InvalidCard        ErrorCode = "invalid-card"
+	NotPossible        ErrorCode = "not-possible"
+	GameOver           ErrorCode = "game-over"
+	UnknownMatch       ErrorCode = "unknown-match"
+	ReadOnly           ErrorCode = "read-only"
+	NameTaken          ErrorCode = "name-taken"
+	UnknownTable       ErrorCode = "unknown-table"
+	RateLimited        ErrorCode = "rate-limited"
+)
+
+// ErrorBody describes what went wrong.
+type ErrorBody struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// MaxSize is the maximum size of a frame without the length.
+const MaxSize = 1 << 16
+
+// Errors returned while reading frames.
+var (
+	ErrTooLarge = errors.New("Message too large")
+	ErrEmpty    = errors.New("Message without type")
+)
+
+// Message is a single frame.
+type Message struct {
+	Type Type
+	Body string
+}
+
+// NewMessage returns a message with type t and body encoded as JSON.
+// The message has no body if body is nil.
+func NewMessage(t Type, body interface{}) (Message, error) {
+	if body == nil {
+		return Message{Type: t}, nil
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Type: t, Body: string(encoded)}, nil
+}
+
+// Decode decodes the body of m into v.
+func (m Message) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(m.Body), v)
+}
+
+// Write writes m to w as a single frame.
+func Write(w io.Writer, m Message) error {
+	size := 1 + len(m.Body)
+	if size > MaxSize {
+		return ErrTooLarge
+	}
+
+	frame := make([]byte, 4+size)
+	binary.BigEndian.PutUint32(frame, uint32(size))
+	frame[4] = byte(m.Type)
+	copy(frame[5:], m.Body)
+	_, err := w.Write(frame)
+	return err
+}
+
+// Read reads a single frame from r.
+// It returns io.EOF only if r ends exactly between two frames.
+func Read(r io.Reader) (Message, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size == 0 {
+		return Message{}, ErrEmpty
+	}
+	if size > MaxSize {
+		return Message{}, ErrTooLarge
+	}
+
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Message{}, err
+	}
+	return Message{Type: Type(frame[0]), Body: string(frame[1:])}, nil
+}
+
+// Conn sends and receives messages over a connection.
+// Send can be called from several goroutines.
+type Conn struct {
+	reader   *bufio.Reader
+	writer   io.Writer
+	deadline deadliner // nil if the connection has no read deadline
+	mu       sync.Mutex
+	version  int
+	notation *deck.Notation // nil if the cards are written like X♠
+	hello    HelloBody      // sent by the client
+}
+
+// deadliner is a connection like net.Conn whose reads can time out.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// NewConn returns a Conn which uses rw.
+func NewConn(rw io.ReadWriter) *Conn {
+	deadline, _ := rw.(deadliner)
+	return &Conn{reader: bufio.NewReader(rw), writer: rw, deadline: deadline}
+}
+
+// Send sends a message with type t and body encoded as JSON. After the
+// handshake a message the peer doesn't know in its version isn't sent.
+func (c *Conn) Send(t Type, body interface{}) error {
+	if !c.Knows(t) {
+		return nil
+	}
+	if c.notation != nil {
+		var err error
+		if body, err = notated(body, c.notation); err != nil {
+			return err
+		}
+	}
+	m, err := NewMessage(t, body)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Write(c.writer, m)
+}
+
+// SendError sends an error with the given code and message.
+func (c *Conn) SendError(code ErrorCode, message string) error {
+	return c.Send(Error, ErrorBody{Code: code, Message: message})
+}
+
+// Receive waits for the next message. Its cards can be in any notation,
+// because deck.Card reads them all.
+func (c *Conn) Receive() (Message, error) {
+	return Read(c.reader)
+}
+
+// Received is a message read by Listen or the error which has ended the connection.
+type Received struct {
+	Message Message
+	Err     error
+}
+
+// Listen reads the messages into messages until there is an error, which is sent
+// last, or done is closed. It answers Ping itself, so the server hears from the
+// client while it is busy, and since Heartbeats it gives up on a server which
+// has been silent for HeartbeatTimeout if the connection has read deadlines.
+func (c *Conn) Listen(messages chan<- Received, done <-chan struct{}) {
+	for {
+		if c.deadline != nil && c.version >= Heartbeats {
+			c.deadline.SetReadDeadline(time.Now().Add(HeartbeatTimeout))
+		}
+		m, err := c.Receive()
+		if err == nil && m.Type == Ping {
+			err = c.Send(Pong, nil)
+			if err == nil {
+				continue
+			}
+		}
+
+		select {
+		case messages <- Received{m, err}:
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Handshake sends Hello and waits for Welcome. It returns the version picked by the server.
+func (c *Conn) Handshake() (int, error) {
+	return c.HandshakeWith(HelloBody{})
+}
+
+// HandshakeWith is like Handshake but sends hello with the versions this package speaks.
+// If the server doesn't know the notation in hello, the cards are written like X♠.
+func (c *Conn) HandshakeWith(hello HelloBody) (int, error) {
+	hello.Versions = Versions
+	if err := c.Send(Hello, hello); err != nil {
+		return 0, err
+	}
+
+	m, err := c.Receive()
+	if err != nil {
+		return 0, err
+	}
+	switch m.Type {
+	case Welcome:
+		var welcome WelcomeBody
+		if err := m.Decode(&welcome); err != nil {
+			return 0, err
+		}
+		c.version = welcome.Version
+		if welcome.Notation != "" && welcome.Notation == hello.Notation {
+			c.notation = deck.FindNotation(hello.Notation)
+		}
+		return welcome.Version, nil
+	case Error:
+		var e ErrorBody
+		if err := m.Decode(&e); err != nil {
+			return 0, err
+		}
+		return 0, errors.New(e.Message)
+	}
+	return 0, errors.New("Unexpected message: " + m.Type.String())
+}
+
+// Accept waits for Hello and answers it. It returns the version both sides speak.
+func (c *Conn) Accept() (int, error) {
+	m, err := c.Receive()
+	if err != nil {
+		return 0, err
+	}
+
+	var hello HelloBody
+	if m.Type != Hello || m.Decode(&hello) != nil {
+		c.SendError(BadMessage, "Expected hello")
+		return 0, errors.New("Expected hello, got " + m.Type.String())
+	}
+
+	version, ok := Negotiate(hello.Versions)
+	if !ok {
+		supported := make([]string, len(Versions))
+		for i, v := range Versions {
+			supported[i] = strconv.Itoa(v)
+		}
+		c.SendError(UnsupportedVersion, "Supported versions: "+strings.Join(supported, ", "))
+		return 0, errors.New("Unsupported versions")
+	}
+	if hello.Notation != "" && deck.FindNotation(hello.Notation) == nil {
+		c.SendError(BadMessage, "Supported notations: "+strings.Join(deck.NotationNames(), ", "))
+		return 0, errors.New("Unknown notation " + hello.Notation)
+	}
+	c.version, c.hello = version, hello
+	if err := c.Send(Welcome, WelcomeBody{Version: version, Notation: hello.Notation}); err != nil {
+		return 0, err
+	}
+	c.notation = deck.FindNotation(hello.Notation)
+	return version, nil
+}
+
+// Version returns the version picked in the handshake or 0 before it.
+func (c *Conn) Version() int {
+	return c.version
+}
+
+// Knows returns true if messages with type t can be sent in the version
+// picked in the handshake. Before the handshake every type can be sent.
+func (c *Conn) Knows(t Type) bool {
+	return c.version == 0 || c.version >= since[t]
+}
+
+// Token returns the token the client has sent in Hello or an empty string.
+func (c *Conn) Token() string {
+	return c.hello.Token
+}
+
+// Watch returns the id of the match the client wants to watch or an empty string
+// and whether he wants to be a commentator.
+func (c *Conn) Watch() (string, bool) {
+	return c.hello.Watch, c.hello.Commentator
+}
+
+// Password returns the password of the commentators the client has sent in Hello
+// or an empty string.
+func (c *Conn) Password() string {
+	return c.hello.Password
+}
+
+// Lobby returns the name of the player and whether he wants to go to the lobby.
+func (c *Conn) Lobby() (string, bool) {
+	return c.hello.Name, c.hello.Lobby
+}
+
+// cardType is the type of the cards in the bodies.
+var cardType = reflect.TypeOf(deck.NoCard)
+
+// notatedType returns t with every card in it replaced by a string, keeping the
+// names and tags of the fields, and whether t has any cards.
+func notatedType(t reflect.Type) (reflect.Type, bool) {
+	if t == cardType {
+		return reflect.TypeOf(""), true
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if elem, ok := notatedType(t.Elem()); ok {
+			return reflect.PtrTo(elem), true
+		}
+	case reflect.Slice:
+		if elem, ok := notatedType(t.Elem()); ok {
+			return reflect.SliceOf(elem), true
+		}
+	case reflect.Array:
+		if elem, ok := notatedType(t.Elem()); ok {
+			return reflect.ArrayOf(t.Len(), elem), true
+		}
+	case reflect.Struct:
+		var fields []reflect.StructField
+		cards := false
+		for idx := 0; idx < t.NumField(); idx++ {
+			field := t.Field(idx)
+			if field.PkgPath != "" {
+				continue // unexported, so it isn't encoded
+			}
+			if notated, ok := notatedType(field.Type); ok {
+				field.Type, cards = notated, true
+			}
+			fields = append(fields, field)
+		}
+		if cards {
+			return reflect.StructOf(fields), true
+		}
+	}
+	return t, false
+}
+
+// notate returns v converted to t from notatedType with every card written in n.
+func notate(v reflect.Value, t reflect.Type, n *deck.Notation) (reflect.Value, error) {
+	if v.Type() == t {
+		return v, nil
+	}
+
+	converted := reflect.New(t).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return converted, nil
+		}
+		elem, err := notate(v.Elem(), t.Elem(), n)
+		if err != nil {
+			return converted, err
+		}
+		converted.Set(reflect.New(t.Elem()))
+		converted.Elem().Set(elem)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return converted, nil
+			}
+			converted.Set(reflect.MakeSlice(t, v.Len(), v.Len()))
+		}
+		for idx := 0; idx < v.Len(); idx++ {
+			elem, err := notate(v.Index(idx), t.Elem(), n)
+			if err != nil {
+				return converted, err
+			}
+			converted.Index(idx).Set(elem)
+		}
+	case reflect.Struct:
+		field := 0
+		for idx := 0; idx < v.NumField(); idx++ {
+			if v.Type().Field(idx).PkgPath != "" {
+				continue
+			}
+			value, err := notate(v.Field(idx), t.Field(field).Type, n)
+			if err != nil {
+				return converted, err
+			}
+			converted.Field(field).Set(value)
+			field++
+		}
+	default:
+		card := v.Interface().(deck.Card)
+		if card != deck.NoCard && !card.IsValid() {
+			return converted, deck.ErrBadCard
+		}
+		converted.SetString(n.Format(card))
+	}
+	return converted, nil
+}
+
+// notated returns body with every card in it written in n, encoded as JSON
+// with the same keys, or body itself if it has no cards.
+func notated(body interface{}, n *deck.Notation) (interface{}, error) {
+	if body == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(body)
+	t, ok := notatedType(v.Type())
+	if !ok {
+		return body, nil
+	}
+	converted, err := notate(v, t, n)
+	if err != nil {
+		return nil, err
+	}
+	return converted.Interface(), nil
+}
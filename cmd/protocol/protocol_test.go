@@ -0,0 +1,278 @@
+package protocol
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+var messages = []Message{
+	{Hello, `{"versions":[1]}`},
+	{Play, `{"card":"X♠"}`},
+	{Waiting, ""},
+	{State, `{"hand":["X♠","K♠","J♥","9♣","K♥","K♣"],"trump":"A♥"}`},
+	{TrickResult, `{"winner":1,"points":14}`},
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buff bytes.Buffer
+	for _, m := range messages {
+		if err := Write(&buff, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, m := range messages {
+		got, err := Read(&buff)
+		if err != nil || got != m {
+			t.Error("Read error!", got, err)
+		}
+	}
+
+	if _, err := Read(&buff); err != io.EOF {
+		t.Error("Expected EOF!", err)
+	}
+}
+
+func TestSplitFrames(t *testing.T) {
+	var buff bytes.Buffer
+	for _, m := range messages {
+		Write(&buff, m)
+	}
+
+	c := NewConn(struct {
+		io.Reader
+		io.Writer
+	}{iotest.OneByteReader(&buff), io.Discard})
+	for _, m := range messages {
+		got, err := c.Receive()
+		if err != nil || got != m {
+			t.Error("Receive error!", got, err)
+		}
+	}
+}
+
+func TestTruncatedFrame(t *testing.T) {
+	var buff bytes.Buffer
+	Write(&buff, Message{Played, `{"seat":0,"card":"A♥"}`})
+	frame := buff.Bytes()
+
+	if _, err := Read(bytes.NewReader(frame[:len(frame)-1])); err != io.ErrUnexpectedEOF {
+		t.Error("Expected unexpected EOF!", err)
+	}
+	if _, err := Read(bytes.NewReader(frame[:2])); err != io.ErrUnexpectedEOF {
+		t.Error("Expected unexpected EOF!", err)
+	}
+}
+
+func TestInvalidSize(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte{0, 0, 0, 0})); err != ErrEmpty {
+		t.Error("Expected empty message error!", err)
+	}
+	if _, err := Read(bytes.NewReader([]byte{0xff, 0, 0, 0, 1})); err != ErrTooLarge {
+		t.Error("Expected too large error!", err)
+	}
+	if err := Write(io.Discard, Message{State, string(make([]byte, MaxSize))}); err != ErrTooLarge {
+		t.Error("Expected too large error!", err)
+	}
+}
+
+func TestBody(t *testing.T) {
+	score := [2]int{7, 3}
+	bodies := []interface{}{
+		&StateBody{Hand: []deck.Card{deck.MustParse("Q♥"), deck.NoCard}, Trump: deck.MustParse("A♥"), DeckSize: 9, Score: 40, GameScore: score, Turn: 1},
+		&PlayedBody{Seat: 1, Card: deck.MustParse("K♥"), Marriage: 40},
+		&ResultBody{Winner: 0, Points: 2, Score: &score},
+		&ErrorBody{Code: InvalidCard, Message: "Card cannot be played"},
+	}
+
+	for _, body := range bodies {
+		m, err := NewMessage(State, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(body).Elem()).Interface()
+		if err := m.Decode(decoded); err != nil || !reflect.DeepEqual(decoded, body) {
+			t.Error("Decode error!", m.Body, err)
+		}
+	}
+
+	var play PlayBody
+	if err := (Message{Play, `{"card":"X♠♠"}`}).Decode(&play); err == nil {
+		t.Error("Expected bad card!")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	if v, ok := Negotiate([]int{0, Version, Version + 1}); !ok || v != Version {
+		t.Error("Negotiate error!")
+	}
+	if _, ok := Negotiate([]int{Version + 1}); ok {
+		t.Error("Negotiate error!")
+	}
+	if v, ok := Negotiate([]int{1}); !ok || v != 1 {
+		t.Error("Negotiate error!")
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go NewConn(server).Accept()
+	c := NewConn(client)
+	if v, err := c.Handshake(); err != nil || v != Version || c.Version() != Version {
+		t.Error("Handshake error!", err)
+	}
+}
+
+func TestHandshakeUnsupportedVersion(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go NewConn(server).Accept()
+	c := NewConn(client)
+	c.Send(Hello, HelloBody{Versions: []int{Version + 1}})
+	m, err := c.Receive()
+	var e ErrorBody
+	if err != nil || m.Type != Error || m.Decode(&e) != nil || e.Code != UnsupportedVersion {
+		t.Error("Expected unsupported version!", m, err)
+	}
+	if !strings.Contains(e.Message, "1, 2") {
+		t.Error("Expected all supported versions!", e.Message)
+	}
+}
+
+func TestOlderVersion(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	s := NewConn(server)
+	go func() {
+		if _, err := s.Accept(); err != nil {
+			return
+		}
+		s.Send(Commitments, CommitmentsBody{})
+		s.Send(Waiting, nil)
+	}()
+
+	c := NewConn(client)
+	c.Send(Hello, HelloBody{Versions: []int{1}})
+	if m, err := c.Receive(); err != nil || m.Type != Welcome {
+		t.Fatal("Handshake error!", m, err)
+	}
+	if m, err := c.Receive(); err != nil || m.Type != Waiting {
+		t.Error("Expected only the messages of version 1!", m, err)
+	}
+}
+
+func TestHandshakeNotation(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	s := NewConn(server)
+	go func() {
+		if _, err := s.Accept(); err != nil {
+			return
+		}
+		s.Send(State, StateBody{Hand: []deck.Card{deck.MustParse("X♠"), deck.NoCard}, Trump: deck.MustParse("Q♥")})
+		m, _ := s.Receive()
+		var play PlayBody
+		m.Decode(&play)
+		s.Send(Played, PlayedBody{Card: play.Card})
+	}()
+
+	c := NewConn(client)
+	if _, err := c.HandshakeWith(HelloBody{Notation: "ascii"}); err != nil {
+		t.Fatal("Handshake error!", err)
+	}
+	m, err := Read(c.reader)
+	if err != nil || m.Body != `{"hand":["TS",""],"trump":"QH","deckSize":0,"closed":false,"table":"","score":0,"gameScore":[0,0],"turn":0}` {
+		t.Error("Notation error!", m.Body, err)
+	}
+
+	Write(client, Message{Play, `{"card":"ah"}`})
+	m, err = c.Receive()
+	var played PlayedBody
+	if err != nil || m.Decode(&played) != nil || played.Card != deck.MustParse("A♥") {
+		t.Error("Notation error!", m.Body, err)
+	}
+}
+
+func TestHandshakeUnknownNotation(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go NewConn(server).Accept()
+	c := NewConn(client)
+	if _, err := c.HandshakeWith(HelloBody{Notation: "klingon"}); err == nil {
+		t.Error("Expected unknown notation!")
+	}
+}
+
+func TestListen(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	s, c := NewConn(server), NewConn(client)
+	errs := make(chan error)
+	go func() {
+		_, err := c.Handshake()
+		errs <- err
+	}()
+	if _, err := s.Accept(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan Received)
+	done := make(chan struct{})
+	defer close(done)
+	go c.Listen(messages, done)
+
+	go s.Send(Ping, nil)
+	if m, err := s.Receive(); err != nil || m.Type != Pong {
+		t.Error("Expected pong!", m, err)
+	}
+	go s.Send(Waiting, nil)
+	if r := <-messages; r.Err != nil || r.Message.Type != Waiting {
+		t.Error("Listen error!", r)
+	}
+}
+
+func TestNotated(t *testing.T) {
+	view := ViewBody{Hands: [][]deck.Card{{deck.MustParse("X♠"), deck.NoCard}, {deck.MustParse("Q♥")}}, Turn: 1, Clock: &ClockBody{Move: 5}}
+	body, err := notated(view, deck.ASCII)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMessage(View, body)
+	if err != nil || m.Body != `{"hands":[["TS",""],["QH"]],"trump":"","deckSize":0,"closed":false,"table":["",""],"score":[0,0],"gameScore":[0,0],"turn":1,"clock":{"move":5,"game":[0,0]}}` {
+		t.Error("Notation error!", m.Body, err)
+	}
+
+	var decoded ViewBody
+	if err := m.Decode(&decoded); err != nil || !reflect.DeepEqual(decoded, view) {
+		t.Error("Decode error!", decoded, err)
+	}
+	if _, err := notated(PlayBody{Card: deck.Card(0xff)}, deck.ASCII); err != deck.ErrBadCard {
+		t.Error("Expected bad card!", err)
+	}
+	if body, _ := notated(SeatBody{Seat: 1}, deck.ASCII); body != (SeatBody{Seat: 1}) {
+		t.Error("Expected the same body!", body)
+	}
+}
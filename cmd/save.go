@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/clock"
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/fair"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// saveVersion is the version of the saved matches written by the server.
+const saveVersion = 1
+
+// errSaveVersion is returned for a match saved by a newer server.
+var errSaveVersion = errors.New("Unknown version of the saved match")
+
+// savedMatch is a match written to disk, so it can go on after the server restarts.
+// The game is written by sixtysix.MarshalState. Left is the time left of the game
+// of each player if the time is limited.
+type savedMatch struct {
+	Version int              `json:"version"`
+	Tokens  [2]string        `json:"tokens"`
+	Game    json.RawMessage  `json:"game"`
+	Dealer  *fair.Dealer     `json:"dealer,omitempty"`
+	Rated   bool             `json:"rated,omitempty"`
+	Control *clock.Control   `json:"control,omitempty"`
+	Left    [2]time.Duration `json:"left,omitempty"`
+}
+
+// newToken returns a random token which a player cannot guess.
+func newToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// save writes the match to m.file if it is saved.
+// The old file is replaced only after the new one is written.
+func (m *match) save() {
+	if m.file == "" {
+		return
+	}
+
+	game, err := sixtysix.MarshalState(m.game.State())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	saved := savedMatch{Version: saveVersion, Tokens: m.tokens, Game: game, Dealer: m.dealer, Rated: m.rated}
+	if m.clock != nil {
+		saved.Control = &m.clock.Control
+		_, saved.Left = m.clock.Left(time.Now())
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := ioutil.WriteFile(m.file+".tmp", data, 0600); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := os.Rename(m.file+".tmp", m.file); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// forget removes the saved match after it is over.
+func (m *match) forget() {
+	if m.file != "" {
+		os.Remove(m.file)
+	}
+}
+
+// loadMatch reads a match saved in file and returns it without players.
+func loadMatch(file string) (*match, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var saved savedMatch
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	if saved.Version < 1 || saved.Version > saveVersion {
+		return nil, errSaveVersion
+	}
+	state, err := sixtysix.UnmarshalState(saved.Game)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMatch(nil, nil, nil)
+	m.tokens, m.file, m.rated, m.resumed = saved.Tokens, file, saved.Rated, true
+	switch {
+	case saved.Dealer != nil:
+		m.dealer, m.deal = saved.Dealer, state.Deal
+		m.game = sixtysix.RestoreWithDeals(state, dealSources(saved.Dealer))
+	case saved.Rated:
+		m.game = sixtysix.RestoreWithSource(state, deck.CryptoSource{})
+	default:
+		m.game = sixtysix.Restore(state)
+	}
+	if saved.Control != nil {
+		m.clock = clock.Restore(*saved.Control, saved.Left)
+	}
+	return m, nil
+}
+
+// loadMatches reads all matches saved in dir. The matches which cannot
+// be read are skipped.
+func loadMatches(dir string) ([]*match, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*match
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		m, err := loadMatch(filepath.Join(dir, file.Name()))
+		if err != nil {
+			fmt.Println(file.Name()+":", err)
+			continue
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
@@ -1,220 +1,459 @@
 package main
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
-	"strings"
 	"sync"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/clock"
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// player is a connected client. Everything he sends is put in inputs,
+// which is closed when the connection is lost.
+type player struct {
+	conn   net.Conn
+	proto  *protocol.Conn
+	inputs chan protocol.Message
+	alive  chan struct{} // gets a value when anything comes from the client
+	health chan bool     // false when the connection becomes unstable and true when it is fine again
+	gone   chan struct{}
+	closed chan struct{}
+	once   sync.Once
+}
+
+// unstableBeats and lostBeats are how many heartbeats without anything from
+// a client make his connection unstable and lost.
+const (
+	unstableBeats = 2
+	lostBeats     = 4
 )
 
-// deckInfoMsg returns suitable for sending string containing deck info.
-func deckInfoMsg() string {
-	deckSize := len(g.deck.Current)
-	if deckSize != 0 {
-		deckSize++ // counting the trump
+// newPlayer starts listening to what the client on conn sends through proto.
+// If the client speaks Heartbeats, he is pinged every heartbeat.
+func newPlayer(conn net.Conn, proto *protocol.Conn, heartbeat time.Duration) *player {
+	p := &player{
+		conn:   conn,
+		proto:  proto,
+		inputs: make(chan protocol.Message, 16),
+		alive:  make(chan struct{}, 1),
+		health: make(chan bool, 1),
+		gone:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go p.listen()
+	if heartbeat > 0 && proto.Version() >= protocol.Heartbeats {
+		go p.beat(heartbeat)
 	}
+	return p
+}
+
+// listen reads from the connection until it is closed.
+func (p *player) listen() {
+	defer close(p.gone)
+	defer close(p.inputs)
+
+	for {
+		message, err := p.proto.Receive()
+		if err != nil {
+			return
+		}
+		select {
+		case p.alive <- struct{}{}:
+		default:
+		}
+		if message.Type == protocol.Pong {
+			continue
+		}
 
-	return "Trump: " + replaceTens(g.trump) +
-		"\tDeck size: " + strconv.Itoa(deckSize) +
-		"\tClosed: " + strconv.FormatBool(g.isClosed()) + "\n"
+		select {
+		case p.inputs <- message:
+		case <-p.closed:
+			return
+		}
+	}
 }
 
-// handMsg returns suitable for sending string containing player's hand.
-func handMsg(player int) string {
-	return "Your hand: " + replaceTens(strings.Join(g.hands[player], " ")) + "\n"
+// beat pings the client every interval until the connection is lost.
+// If nothing comes from him for lostBeats intervals, the connection is closed.
+func (p *player) beat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	missed := 0
+	for {
+		select {
+		case <-p.alive:
+			if missed >= unstableBeats {
+				p.report(true)
+			}
+			missed = 0
+		case <-ticker.C:
+			missed++
+			if missed == unstableBeats {
+				p.report(false)
+			}
+			if missed >= lostBeats {
+				p.conn.Close()
+				return
+			}
+			p.send(protocol.Ping, nil)
+		case <-p.gone:
+			return
+		}
+	}
 }
 
-// pointsMsg returns suitable for sending string containing deal and g points.
-func pointsMsg(player int) string {
-	return "Deal points: " + strconv.Itoa(g.dealScore[player]) +
-		"\tGame points: " + strconv.Itoa(g.gameScore[player]) +
-		":" + strconv.Itoa(g.gameScore[opponentOf(player)]) + "\n"
+// report puts in health whether the connection is fine, replacing what hasn't been read yet.
+func (p *player) report(fine bool) {
+	select {
+	case <-p.health:
+	default:
+	}
+	p.health <- fine
 }
 
-// sendTurnInfo sends info about the deck, hands and points to each player.
-func sendTurnInfo() {
-	info := "\n" + handMsg(g.playerInTurn) +
-		deckInfoMsg() + pointsMsg(g.playerInTurn) + YourTurn
-	sendTo(g.playerInTurn, info)
+// isGone returns true if the connection is lost.
+func (p *player) isGone() bool {
+	select {
+	case <-p.gone:
+		return true
+	default:
+		return false
+	}
+}
 
-	info = "\n" + handMsg(g.playerNotInTurn()) +
-		deckInfoMsg() + pointsMsg(g.playerNotInTurn()) + OpponentTurn
-	sendTo(g.playerNotInTurn(), info)
+// send sends a message with type t and the given body to the player.
+func (p *player) send(t protocol.Type, body interface{}) {
+	p.proto.Send(t, body)
 }
 
-// replaceTens gets a hand and replaces the tens to be suitable for printing.
-func replaceTens(hand string) string {
-	return strings.Replace(hand, "X", "10", -1)
+// close closes the connection.
+func (p *player) close() {
+	p.once.Do(func() {
+		close(p.closed)
+		p.conn.Close()
+	})
 }
 
-// sendTo sends message to player.
-func sendTo(player int, message string) {
-	players[player].Write([]byte(message))
+// settings are how the server runs the matches.
+type settings struct {
+	seed      int64         // decides the deals of the next match if seeded
+	seeded    bool          // the deals are repeated from seed
+	rated     bool          // the deals cannot be predicted
+	saveDir   string        // where the matches are saved if not empty
+	grace     time.Duration // how long a player who has lost the connection keeps his seat
+	timeout   time.Duration // how long a player has to introduce himself and to take part in the deals
+	control   clock.Control // the time control of the matches
+	heartbeat time.Duration // how often the players are pinged
+	hints     bool          // the players can ask for hints, only in the games against a bot
+	password  string        // of the commentators, nobody can be one if it is empty
 }
 
-// exit informs players if someone quits and closes the connections.
-func exit(player int) {
-	if connected == 2 {
-		if player != Nobody {
-			sendTo(opponentOf(player), OpponentLeft)
-		}
-		players[Player2].Close()
+// server pairs the connecting players into matches.
+type server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	waiting  *player
+	matches  map[*match]bool
+	saved    map[string]*match // the saved matches waiting for their players, by their tokens
+	sessions map[string]*match // the running matches by the tokens of their players
+	lastID   int               // the id of the last match which has started
+	guests   map[*guest]bool   // the players in the lobby
+	tables   map[string]*table // the tables in the lobby by their names
+	settings
+}
+
+// handshakeTimeout is how long a new client has to introduce himself
+// and to take part in the fair deals by default.
+const handshakeTimeout = 10 * time.Second
+
+// defaultGrace is how long a player who has lost the connection keeps his seat by default.
+const defaultGrace = time.Minute
+
+// startServer starts listening on addr.
+func startServer(addr string) (*server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server{
+		listener: listener,
+		matches:  make(map[*match]bool),
+		saved:    make(map[string]*match),
+		sessions: make(map[string]*match),
+		guests:   make(map[*guest]bool),
+		tables:   make(map[string]*table),
+		settings: settings{grace: defaultGrace, timeout: handshakeTimeout, heartbeat: protocol.HeartbeatInterval},
+	}, nil
+}
+
+// load resumes the matches saved in s.saveDir.
+func (s *server) load() error {
+	if err := os.MkdirAll(s.saveDir, 0700); err != nil {
+		return err
+	}
+	matches, err := loadMatches(s.saveDir)
+	if err != nil {
+		return err
 	}
-	players[Player1].Close()
-	server.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range matches {
+		s.saved[m.tokens[sixtysix.Player1]] = m
+		s.saved[m.tokens[sixtysix.Player2]] = m
+	}
+	return nil
 }
 
-// listenTo listens what player sends and responds accordingly.
-func listenTo(player int) {
-	buff := make([]byte, 256)
+// serve accepts connections until the server is closed.
+func (s *server) serve() {
 	for {
-		size, err := players[player].Read(buff)
+		connection, err := s.listener.Accept()
 		if err != nil {
-			exit(player)
-			return
-		}
-
-		m := string(buff)[:size]
-		if size == 2 && m[0] >= '1' && m[0] <= '6' {
-			cardIdx := int(m[0] - '1')
-			if !g.isCardValid(player, cardIdx) {
-				sendTo(player, WrongInput)
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
 				continue
 			}
+			return
+		}
+		go s.handshake(connection)
+	}
+}
 
-			card := g.playerPlayed(player, cardIdx)
-			msg := OpponentCard + replaceTens(card)
-
-			hasMarriage, pts := g.checkForMarriage(player, card)
-			if hasMarriage {
-				g.addMarriagePoints(player)
-				marriage := "Marriage: " + strconv.Itoa(pts) + "\n"
-				sendTo(player, marriage)
-				msg += " " + marriage
-			} else {
-				msg += "\n"
-			}
-			sendTo(g.playerNotInTurn(), msg)
-
-			if g.trick[opponentOf(player)] == NoCard {
-				sendTo(player, OpponentTurn)
-				g.playerInTurn = g.playerNotInTurn()
-				sendTo(g.playerInTurn, YourTurn)
-			} else {
-				winner := g.findWinner()
-				g.playerInTurn = winner
-				g.hasTrickWon[winner] = true
-
-				g.addMarriagePoints(winner)
-				g.dealScore[winner] += g.trickPoints()
-
-				sendTo(g.playerInTurn, WonTrick)
-				sendTo(g.playerNotInTurn(), LostTrick)
-				g.trick[Player1] = NoCard
-				g.trick[Player2] = NoCard
-
-				g.draw()
-				if len(g.hands[player]) == 0 {
-					if !g.isClosed() {
-						g.dealScore[g.playerInTurn] += LastTrickBonus
-					}
-					winner, pts := g.endDeal(Nobody)
-					ptsStr := strconv.Itoa(pts)
-					sendTo(winner, WonDeal+ptsStr+"\n")
-					sendTo(opponentOf(winner), LostDeal+ptsStr+"\n")
-					sendTurnInfo()
-				} else {
-					sendTurnInfo()
-				}
-			}
-			continue
+// handshake waits for Hello and then pairs the client with the one waiting.
+func (s *server) handshake(connection net.Conn) {
+	connection.SetReadDeadline(time.Now().Add(s.timeout))
+	proto := protocol.NewConn(connection)
+	if _, err := proto.Accept(); err != nil {
+		connection.Close()
+		return
+	}
+	connection.SetReadDeadline(time.Time{})
+	p := newPlayer(connection, proto, s.heartbeat)
+	if id, commentator := proto.Watch(); id != "" {
+		if proto.Version() < protocol.Spectators {
+			proto.SendError(protocol.UnsupportedVersion, "Watching needs version "+strconv.Itoa(protocol.Spectators))
+			p.close()
+			return
 		}
+		if commentator && !s.isCommentator(proto.Password()) {
+			proto.SendError(protocol.NotPossible, "Wrong password for the commentators")
+			p.close()
+			return
+		}
+		s.watch(p, id, commentator)
+		return
+	}
 
-		var success bool
-		switch m {
-		case Close:
-			success = g.close(player)
-			if success {
-				sendTo(g.playerNotInTurn(), OpponentClosed)
-				sendTurnInfo()
-			} else {
-				sendTo(player, NotPossible)
-			}
-		case Exchange:
-			success = g.exchange(player)
-			if success {
-				sendTo(g.playerNotInTurn(), OpponentExchanged)
-				sendTurnInfo()
-			} else {
-				sendTo(player, NotPossible)
-			}
-		case Stop:
-			success, winner, pts := g.stop(player)
-			if success {
-				ptsStr := strconv.Itoa(pts) + "\n"
-				sendTo(winner, WonDeal+ptsStr)
-				sendTo(opponentOf(winner), LostDeal+ptsStr)
-				sendTurnInfo()
-			} else {
-				sendTo(player, NotPossible)
-			}
-		case Help:
-			sendTo(player, Commands+YourTurn)
-		case Quit:
-			exit(player)
-		default:
-			sendTo(player, NotPossible)
+	token := proto.Token()
+	if token != "" {
+		s.mu.Lock()
+		m := s.sessions[token]
+		s.mu.Unlock()
+		if m != nil && m.rejoin(p) {
+			return
 		}
 	}
+
+	if name, lobby := proto.Lobby(); lobby && token == "" && proto.Version() >= protocol.Lobbies {
+		s.enter(p, name)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token != "" {
+		s.resume(p, token)
+		return
+	}
+	if s.waiting != nil && s.waiting.isGone() {
+		s.waiting.close()
+		s.waiting = nil
+	}
+
+	if s.waiting == nil {
+		s.waiting = p
+		p.send(protocol.Waiting, nil)
+		return
+	}
+
+	first := s.waiting
+	s.waiting = nil
+	s.pair(first, p, sixtysix.WinningPoints, s.control)
 }
 
-var (
-	server    net.Listener
-	err       error
-	wg        sync.WaitGroup
-	players   [2]net.Conn
-	g         = new(game)
-	connected = 0
-)
+// isWaiting returns true if a player waits for an opponent.
+func (s *server) isWaiting() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiting != nil && !s.waiting.isGone()
+}
 
-// startServer starts a server and waits for two players to connect.
-func startServer() {
-	server, err = net.Listen("tcp", ":0")
-	if err != nil {
-		fmt.Println(err)
+// pair starts a match between first and second which is won with target points
+// and whose time is limited by control. It must be called with s.mu locked.
+func (s *server) pair(first, second *player, target int, control clock.Control) {
+	game := sixtysix.New()
+	if s.rated {
+		game = sixtysix.NewWithSource(deck.CryptoSource{})
+	} else if s.seeded {
+		fmt.Println("Match seed:", s.seed)
+		game = sixtysix.NewSeeded(s.seed)
+		s.seed++
+	}
+	game.SetTarget(target)
+	m := newMatch(game, first, second)
+	// the deals of a seed are repeated, so they cannot be fair
+	m.fair = !s.seeded && first.proto.Version() >= protocol.FairDeals && second.proto.Version() >= protocol.FairDeals
+	m.rated, m.hints = s.rated, s.hints && !s.rated
+	if control.IsSet() {
+		m.clock = clock.New(control)
+	}
+	if !s.prepare(m) {
+		m.close()
 		return
 	}
-	wg.Done()
+	s.start(m)
+}
 
-	for {
-		connection, err := server.Accept()
+// prepare gives the players of m their tokens and picks the file of m
+// if the matches are saved. It returns false if there is no randomness for the tokens.
+func (s *server) prepare(m *match) bool {
+	for player := range m.tokens {
+		token, err := newToken()
 		if err != nil {
 			fmt.Println(err)
-			return
+			return false
 		}
+		m.tokens[player] = token
+	}
+	if s.saveDir == "" {
+		return true
+	}
 
-		buff := make([]byte, 16)
-		size, e := connection.Read(buff)
-		if e != nil {
-			fmt.Println(err)
+	name, err := newToken()
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	m.file = filepath.Join(s.saveDir, name+".json")
+	return true
+}
+
+// resume puts p in his seat of the saved match with token and starts the match
+// if the other player is back too.
+func (s *server) resume(p *player, token string) {
+	m, ok := s.saved[token]
+	if !ok {
+		p.proto.SendError(protocol.UnknownMatch, "There is no saved match with this token")
+		p.close()
+		return
+	}
+
+	seat := sixtysix.Player1
+	if m.tokens[sixtysix.Player2] == token {
+		seat = sixtysix.Player2
+	}
+	if m.players[seat] != nil {
+		m.players[seat].close() // the player has come back again
+	}
+	m.players[seat] = p
+
+	if other := m.players[sixtysix.OpponentOf(seat)]; other == nil || other.isGone() {
+		p.send(protocol.Waiting, nil)
+		return
+	}
+	delete(s.saved, m.tokens[sixtysix.Player1])
+	delete(s.saved, m.tokens[sixtysix.Player2])
+	s.start(m)
+}
+
+// isCommentator returns true if password is the password of the commentators.
+func (s *server) isCommentator(password string) bool {
+	return s.password != "" && subtle.ConstantTimeCompare([]byte(password), []byte(s.password)) == 1
+}
+
+// watch lets p watch the running match with id, if he is a commentator with the hands.
+func (s *server) watch(p *player, id string, commentator bool) {
+	s.mu.Lock()
+	var m *match
+	for running := range s.matches {
+		if running.id == id {
+			m = running
+		}
+	}
+	s.mu.Unlock()
+
+	if m == nil || !m.watch(newSpectator(p, commentator)) {
+		p.proto.SendError(protocol.UnknownMatch, "There is no match "+id)
+		p.close()
+	}
+}
+
+// start runs m until it is over. It must be called with s.mu locked.
+// If m is cut off, the players can come back to it from where it was saved.
+func (s *server) start(m *match) {
+	s.lastID++
+	m.id, m.grace, m.timeout = strconv.Itoa(s.lastID), s.grace, s.timeout
+	s.matches[m] = true
+	s.sessions[m.tokens[sixtysix.Player1]] = m
+	s.sessions[m.tokens[sixtysix.Player2]] = m
+	go func() {
+		defer close(m.done) // after the maps are changed, so a player who comes back finds the saved match
+		m.run()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.matches, m)
+		delete(s.sessions, m.tokens[sixtysix.Player1])
+		delete(s.sessions, m.tokens[sixtysix.Player2])
+		if m.file == "" || m.over {
 			return
 		}
+		if saved, err := loadMatch(m.file); err == nil {
+			s.saved[saved.tokens[sixtysix.Player1]] = saved
+			s.saved[saved.tokens[sixtysix.Player2]] = saved
+		}
+	}()
+}
 
-		if string(buff[:size]) == Connect {
-			connected++
-			if connected == 1 {
-				players[Player1] = connection
-				sendTo(Player1, Waiting)
-				go listenTo(Player1)
-			} else {
-				players[Player2] = connection
-				go listenTo(Player2)
-				sendTo(Player1, Start)
-				sendTo(Player2, Start)
-				g.start()
-				sendTurnInfo()
-				break
+// port returns the port the server listens on.
+func (s *server) port() string {
+	_, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		fmt.Println(err)
+	}
+	return port
+}
+
+// close stops listening and ends all matches.
+func (s *server) close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.waiting != nil {
+		s.waiting.close()
+		s.waiting = nil
+	}
+	for m := range s.matches {
+		m.stop()
+	}
+	for g := range s.guests {
+		g.close()
+	}
+	for _, m := range s.saved {
+		for _, p := range m.players {
+			if p != nil {
+				p.close()
 			}
 		}
 	}
@@ -0,0 +1,623 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+	"github.com/DanislavKirov/sixtySix/cmd/clock"
+	"github.com/DanislavKirov/sixtySix/cmd/fair"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// client is a connection to the server used in the tests.
+type client struct {
+	net.Conn
+	proto *protocol.Conn
+	deals *fair.Player
+}
+
+// dial connects a client to s.
+func dial(t *testing.T, s *server) client {
+	return dialWith(t, s, protocol.HelloBody{})
+}
+
+// dialWith connects a client to s which sends hello in the handshake.
+func dialWith(t *testing.T, s *server, hello protocol.HelloBody) client {
+	connection, err := net.Dial("tcp", s.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deals, err := fair.NewPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client{connection, protocol.NewConn(connection), deals}
+	if _, err := c.proto.HandshakeWith(hello); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// expect reads from c until a message with type typ arrives and returns it.
+func expect(t *testing.T, c client, typ protocol.Type) protocol.Message {
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		message, err := c.proto.Receive()
+		if err != nil {
+			t.Fatal("Expected "+typ.String(), err)
+		}
+		if err := c.deals.Handle(c.proto, message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Type == typ {
+			return message
+		}
+	}
+}
+
+func TestServerPairsPlayers(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	go s.serve()
+
+	var clients []client
+	for i := 0; i < 4; i++ {
+		clients = append(clients, dial(t, s))
+		if i%2 == 0 {
+			expect(t, clients[i], protocol.Waiting)
+		}
+	}
+	for _, c := range clients {
+		expect(t, c, protocol.Start)
+		defer c.Close()
+	}
+
+	clients[0].proto.Send(protocol.Quit, nil)
+	expect(t, clients[1], protocol.OpponentLeft)
+
+	third := dial(t, s)
+	defer third.Close()
+	expect(t, third, protocol.Waiting)
+}
+
+func TestServerSkipsLeftPlayer(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	go s.serve()
+
+	first := dial(t, s)
+	expect(t, first, protocol.Waiting)
+	first.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	second := dial(t, s)
+	defer second.Close()
+	expect(t, second, protocol.Waiting)
+}
+
+func TestHintBeforeEmptyDeck(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.hints = true
+	go s.serve()
+
+	first := dial(t, s)
+	defer first.Close()
+	expect(t, first, protocol.Waiting)
+	second := dial(t, s)
+	defer second.Close()
+
+	// both have to answer before the game starts
+	expect(t, first, protocol.Start)
+	expect(t, second, protocol.Commitments)
+	expect(t, first, protocol.State)
+
+	var e protocol.ErrorBody
+	first.proto.Send(protocol.Hint, nil)
+	expect(t, first, protocol.Error).Decode(&e)
+	if e.Code != protocol.NotPossible {
+		t.Error("Expected not possible!", e.Code)
+	}
+}
+
+func TestSeededDeals(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.seed, s.seeded = -1, true // the next match has seed 0, which is a seed too
+	go s.serve()
+
+	for i := 0; i < 2; i++ {
+		first := dial(t, s)
+		defer first.Close()
+		expect(t, first, protocol.Waiting)
+		second := dial(t, s)
+		defer second.Close()
+
+		var start protocol.StartBody
+		if expect(t, second, protocol.Start).Decode(&start); start.Fair {
+			t.Error("The deals of a seed are fair!", i)
+		}
+		// nobody commits and the game starts
+		expect(t, second, protocol.State)
+	}
+}
+
+func TestFairDeals(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	go s.serve()
+
+	first := dial(t, s)
+	defer first.Close()
+	expect(t, first, protocol.Waiting)
+	second := dial(t, s)
+	defer second.Close()
+
+	// the bots check every deal and return *fair.Unfair if one doesn't match
+	errs := make(chan error, 2)
+	for i, c := range []client{first, second} {
+		s, _ := bot.New(bot.Names[i+1], rand.New(rand.NewSource(66)))
+		c.SetReadDeadline(time.Now().Add(10 * time.Second))
+		go func(c client) {
+			errs <- bot.Run(c.proto, s)
+		}(c)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Error("Fair deals error!", err)
+		}
+	}
+}
+
+func TestFairDealsTimeOut(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.timeout = 50 * time.Millisecond
+	go s.serve()
+
+	first := dial(t, s)
+	defer first.Close()
+	expect(t, first, protocol.Waiting)
+	second := dial(t, s)
+	defer second.Close()
+	expect(t, second, protocol.Start)
+
+	// the first player never commits, which is the same as leaving
+	first.SetReadDeadline(time.Now().Add(time.Second))
+	if m, err := first.proto.Receive(); err != nil || m.Type != protocol.Start {
+		t.Fatal("Expected start!", m, err)
+	}
+	var e protocol.ErrorBody
+	if expect(t, first, protocol.Error).Decode(&e); e.Code != protocol.NotPossible {
+		t.Error("Expected not possible!", e)
+	}
+	expect(t, second, protocol.OpponentLeft)
+}
+
+func TestResumeSavedMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sixtySix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.saveDir = dir
+	if err := s.load(); err != nil {
+		t.Fatal(err)
+	}
+	go s.serve()
+
+	players, starts, states := startMatch(t, s)
+	defer players[0].Close()
+	defer players[1].Close()
+
+	// both play the first card of their hands, which is always possible before the deck is empty
+	turn := states[0].Turn
+	players[turn].proto.Send(protocol.Play, protocol.PlayBody{Card: states[turn].Hand[0]})
+	expect(t, players[1-turn], protocol.State).Decode(&states[1-turn])
+	players[1-turn].proto.Send(protocol.Play, protocol.PlayBody{Card: states[1-turn].Hand[0]})
+	for seat, c := range players {
+		expect(t, c, protocol.TrickResult)
+		expect(t, c, protocol.State).Decode(&states[seat])
+	}
+	s.close()
+
+	s, err = startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.saveDir = dir
+	if err := s.load(); err != nil {
+		t.Fatal(err)
+	}
+	go s.serve()
+
+	stranger := dialWith(t, s, protocol.HelloBody{Token: "nobody"})
+	defer stranger.Close()
+	var e protocol.ErrorBody
+	if expect(t, stranger, protocol.Error).Decode(&e); e.Code != protocol.UnknownMatch {
+		t.Error("Expected unknown match!", e.Code)
+	}
+
+	players[1] = dialWith(t, s, protocol.HelloBody{Token: starts[1].Token})
+	defer players[1].Close()
+	expect(t, players[1], protocol.Waiting)
+	players[0] = dialWith(t, s, protocol.HelloBody{Token: starts[0].Token})
+	defer players[0].Close()
+
+	for seat, c := range players {
+		var start protocol.StartBody
+		var state protocol.StateBody
+		expect(t, c, protocol.Start).Decode(&start)
+		expect(t, c, protocol.State).Decode(&state)
+		if !start.Resumed || start.Seat != seat || fmt.Sprint(state) != fmt.Sprint(states[seat]) {
+			t.Error("Resume error!", start, state, states[seat])
+		}
+	}
+}
+
+// startMatch connects two clients to s and waits until the game starts.
+// It returns the clients, their starts and their first states.
+func startMatch(t *testing.T, s *server) ([2]client, [2]protocol.StartBody, [2]protocol.StateBody) {
+	var (
+		players [2]client
+		starts  [2]protocol.StartBody
+		states  [2]protocol.StateBody
+	)
+	players[0] = dial(t, s)
+	expect(t, players[0], protocol.Waiting)
+	players[1] = dial(t, s)
+
+	for seat, c := range players {
+		expect(t, c, protocol.Start).Decode(&starts[seat])
+	}
+	expect(t, players[1], protocol.Commitments)
+	for seat, c := range players {
+		expect(t, c, protocol.State).Decode(&states[seat])
+	}
+	return players, starts, states
+}
+
+func TestReconnect(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	go s.serve()
+
+	players, starts, states := startMatch(t, s)
+	defer players[1].Close()
+	players[0].Close()
+	expect(t, players[1], protocol.OpponentAway)
+
+	back := dialWith(t, s, protocol.HelloBody{Token: starts[0].Token})
+	defer back.Close()
+	var start protocol.StartBody
+	var state protocol.StateBody
+	expect(t, back, protocol.Start).Decode(&start)
+	expect(t, back, protocol.State).Decode(&state)
+	if !start.Resumed || start.Seat != 0 || fmt.Sprint(state) != fmt.Sprint(states[0]) {
+		t.Error("Reconnect error!", start, state, states[0])
+	}
+	expect(t, players[1], protocol.OpponentBack)
+
+	// the game goes on with the new connection
+	turn := []client{back, players[1]}[state.Turn]
+	turn.proto.Send(protocol.Play, protocol.PlayBody{Card: states[state.Turn].Hand[0]})
+	expect(t, back, protocol.Played)
+	expect(t, players[1], protocol.Played)
+}
+
+func TestGracePeriodEnds(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.grace = 50 * time.Millisecond
+	go s.serve()
+
+	players, starts, _ := startMatch(t, s)
+	defer players[0].Close()
+	players[1].Close()
+	expect(t, players[0], protocol.OpponentAway)
+	expect(t, players[0], protocol.OpponentLeft)
+
+	late := dialWith(t, s, protocol.HelloBody{Token: starts[1].Token})
+	defer late.Close()
+	var e protocol.ErrorBody
+	if expect(t, late, protocol.Error).Decode(&e); e.Code != protocol.UnknownMatch {
+		t.Error("Expected unknown match!", e.Code)
+	}
+}
+
+func TestTimeOut(t *testing.T) {
+	for _, penalty := range []clock.Penalty{clock.AutoPlay, clock.ForfeitGame} {
+		s, err := startServer("localhost:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.control = clock.Control{Move: 100 * time.Millisecond, Penalty: penalty}
+		go s.serve()
+
+		players, starts, states := startMatch(t, s)
+		late := 0
+		if states[1].Turn == starts[1].Seat {
+			late = 1
+		}
+		if states[late].Clock == nil || states[late].Clock.Move <= 0 || states[late].Clock.Game[0] != -1 {
+			t.Error("State clock error!", states[late].Clock)
+		}
+		expect(t, players[late], protocol.TimeWarning)
+
+		var out protocol.TimeOutBody
+		expect(t, players[1-late], protocol.TimeOut).Decode(&out)
+		if out.Seat != starts[late].Seat || out.Penalty != penalty.String() {
+			t.Error("TimeOut error!", out)
+		}
+		if penalty == clock.AutoPlay {
+			var played protocol.PlayedBody
+			if expect(t, players[1-late], protocol.Played).Decode(&played); played.Seat != starts[late].Seat {
+				t.Error("Expected the card to be played!", played)
+			}
+		} else {
+			var result protocol.ResultBody
+			if expect(t, players[1-late], protocol.GameResult).Decode(&result); result.Winner != starts[1-late].Seat {
+				t.Error("Expected the game to be lost!", result)
+			}
+		}
+		players[0].Close()
+		players[1].Close()
+		s.close()
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.heartbeat = 20 * time.Millisecond
+	go s.serve()
+
+	players, _, _ := startMatch(t, s)
+	defer players[0].Close()
+	defer players[1].Close()
+
+	// the first player answers the pings and the second one is silent
+	messages := make(chan protocol.Received)
+	done := make(chan struct{})
+	defer close(done)
+	go players[0].proto.Listen(messages, done)
+	timeout := time.After(time.Second)
+	for _, want := range []protocol.Type{protocol.OpponentUnstable, protocol.OpponentAway} {
+		for got := protocol.Type(0); got != want; {
+			select {
+			case r := <-messages:
+				if r.Err != nil {
+					t.Fatal("Expected "+want.String(), r.Err)
+				}
+				got = r.Message.Type
+			case <-timeout:
+				t.Fatal("Expected " + want.String())
+			}
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	s.password = "secret"
+	go s.serve()
+
+	players, starts, states := startMatch(t, s)
+	defer players[0].Close()
+	defer players[1].Close()
+	spectator := dialWith(t, s, protocol.HelloBody{Watch: starts[0].Match})
+	defer spectator.Close()
+	commentator := dialWith(t, s, protocol.HelloBody{Watch: starts[0].Match, Commentator: true, Password: "secret"})
+	defer commentator.Close()
+	cheater := dialWith(t, s, protocol.HelloBody{Watch: starts[0].Match, Commentator: true, Password: "guess"})
+	defer cheater.Close()
+
+	var start protocol.StartBody
+	var view protocol.ViewBody
+	expect(t, spectator, protocol.Start).Decode(&start)
+	if expect(t, spectator, protocol.View).Decode(&view); start.Seat != sixtysix.Nobody || view.Hands != nil {
+		t.Error("Spectator sees the hands!", start, view)
+	}
+	expect(t, commentator, protocol.Start)
+	var e protocol.ErrorBody
+	if expect(t, cheater, protocol.Error).Decode(&e); e.Code != protocol.NotPossible {
+		t.Error("Anybody can see the hands!", e)
+	}
+
+	spectator.proto.Send(protocol.Play, protocol.PlayBody{Card: states[0].Hand[0]})
+	if expect(t, spectator, protocol.Error).Decode(&e); e.Code != protocol.ReadOnly {
+		t.Error("Expected read only!", e)
+	}
+
+	late := 0
+	if states[1].Turn == starts[1].Seat {
+		late = 1
+	}
+	card := states[late].Hand[0]
+	players[late].proto.Send(protocol.Play, protocol.PlayBody{Card: card})
+	var played protocol.PlayedBody
+	if expect(t, spectator, protocol.Played).Decode(&played); played.Card != card || played.Seat != starts[late].Seat {
+		t.Error("Played error!", played)
+	}
+
+	// the commentator sees the deal when it is over, here when the match ends
+	commentator.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if message, err := commentator.proto.Receive(); err == nil {
+		t.Error("The commentator sees the deal before it is over!", message.Type)
+	}
+	players[late].proto.Send(protocol.Quit, nil)
+	if expect(t, commentator, protocol.View).Decode(&view); len(view.Hands) != 2 {
+		t.Error("Commentator error!", view)
+	}
+	expect(t, commentator, protocol.Played)
+	expect(t, commentator, protocol.OpponentLeft)
+
+	unknown := dialWith(t, s, protocol.HelloBody{Watch: "nothing"})
+	defer unknown.Close()
+	if expect(t, unknown, protocol.Error).Decode(&e); e.Code != protocol.UnknownMatch {
+		t.Error("Expected unknown match!", e)
+	}
+}
+
+func TestLobby(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	go s.serve()
+
+	var tables protocol.TablesBody
+	host := dialWith(t, s, protocol.HelloBody{Name: "ana", Lobby: true})
+	defer host.Close()
+	if expect(t, host, protocol.Tables).Decode(&tables); len(tables.Tables) != 0 {
+		t.Error("Expected no tables!", tables)
+	}
+	guest := dialWith(t, s, protocol.HelloBody{Name: "bob", Lobby: true})
+	defer guest.Close()
+	expect(t, guest, protocol.Tables)
+	stranger := dialWith(t, s, protocol.HelloBody{Lobby: true})
+	defer stranger.Close()
+	expect(t, stranger, protocol.Tables)
+
+	again := dialWith(t, s, protocol.HelloBody{Name: "ana", Lobby: true})
+	defer again.Close()
+	var e protocol.ErrorBody
+	if expect(t, again, protocol.Error).Decode(&e); e.Code != protocol.NameTaken {
+		t.Error("Expected name taken!", e)
+	}
+
+	host.proto.Send(protocol.Create, protocol.TableBody{Name: "friday", Target: 5, Clock: "0/10m", Invite: "bob"})
+	expect(t, host, protocol.Waiting)
+	var invitation protocol.TableBody
+	expect(t, guest, protocol.Invited).Decode(&invitation)
+	if invitation.Name != "friday" || invitation.Host != "ana" || invitation.Target != 5 || invitation.Clock != "0s/10m0s/play" {
+		t.Error("Invited error!", invitation)
+	}
+
+	stranger.proto.Send(protocol.List, nil)
+	if expect(t, stranger, protocol.Tables).Decode(&tables); len(tables.Tables) != 0 {
+		t.Error("The stranger sees the private table!", tables)
+	}
+	stranger.proto.Send(protocol.Join, protocol.JoinBody{Name: "friday"})
+	if expect(t, stranger, protocol.Error).Decode(&e); e.Code != protocol.UnknownTable {
+		t.Error("Expected unknown table!", e)
+	}
+	stranger.proto.Send(protocol.Create, protocol.TableBody{Name: "long", Target: fair.MaxTarget + 1})
+	if expect(t, stranger, protocol.Error).Decode(&e); e.Code != protocol.BadMessage {
+		t.Error("The secrets of the fair deals aren't enough for the table!", e)
+	}
+
+	guest.proto.Send(protocol.Join, protocol.JoinBody{Name: "friday"})
+	var starts [2]protocol.StartBody
+	expect(t, host, protocol.Start).Decode(&starts[0])
+	expect(t, guest, protocol.Start).Decode(&starts[1])
+	if starts[0].Seat != sixtysix.Player1 || starts[1].Seat != sixtysix.Player2 || starts[1].Target != 5 {
+		t.Error("Start error!", starts)
+	}
+	expect(t, guest, protocol.Commitments)
+	var state protocol.StateBody
+	if expect(t, host, protocol.State).Decode(&state); state.Clock == nil || state.Clock.Game[0] < 590000 {
+		t.Error("The time control of the table isn't used!", state.Clock)
+	}
+
+	// the spectators are told the target of the game which is really played
+	spectator := dialWith(t, s, protocol.HelloBody{Watch: starts[0].Match})
+	defer spectator.Close()
+	var start protocol.StartBody
+	if expect(t, spectator, protocol.Start).Decode(&start); start.Target != 5 {
+		t.Error("The game isn't played to the target of the table!", start.Target)
+	}
+}
+
+func TestChat(t *testing.T) {
+	s, err := startServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	go s.serve()
+
+	players, starts, _ := startMatch(t, s)
+	defer players[0].Close()
+	defer players[1].Close()
+
+	var said protocol.SaidBody
+	players[0].proto.Send(protocol.Say, protocol.SayBody{Text: "hello\x1b[2J "})
+	if expect(t, players[1], protocol.Said).Decode(&said); said.Text != "hello[2J" || said.Seat != starts[0].Seat {
+		t.Error("Said error!", said)
+	}
+
+	var e protocol.ErrorBody
+	players[1].proto.Send(protocol.Mute, protocol.MuteBody{Muted: true})
+	players[1].proto.Send(protocol.Say, protocol.SayBody{Emote: "boo"})
+	if expect(t, players[1], protocol.Error).Decode(&e); e.Code != protocol.BadMessage {
+		t.Error("Expected bad message!", e)
+	}
+	players[0].proto.Send(protocol.Say, protocol.SayBody{Emote: "gg"})
+	players[0].proto.Send(protocol.Say, protocol.SayBody{Text: "well played"})
+	players[0].proto.Send(protocol.Say, protocol.SayBody{Text: "too much"})
+	if expect(t, players[0], protocol.Error).Decode(&e); e.Code != protocol.RateLimited {
+		t.Error("Expected rate limited!", e)
+	}
+
+	players[1].proto.Send(protocol.Say, protocol.SayBody{})
+	players[1].SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		message, err := players[1].proto.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if message.Type == protocol.Said {
+			t.Error("The muted player is heard!")
+		}
+		if message.Type == protocol.Error {
+			break
+		}
+	}
+}
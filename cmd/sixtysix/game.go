@@ -0,0 +1,509 @@
+// Package sixtysix implements the rules of the card game Sixty-six.
+//
+// A Game keeps the deck, the hands, the trump and the scores of both players
+// and only changes them through Start, Play, Close, Exchange and Stop.
+// Everything else is exposed through read-only accessors.
+package sixtysix
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// Players, the empty place of a card and the scores.
+const (
+	Player1 = 0
+	Player2 = 1
+	Nobody  = 2
+
+	NoCard = deck.NoCard
+
+	LastTrickBonus = 10
+	WinningScore   = 66
+	WinningPoints  = 11
+	ForfeitPoints  = 3 // the most a deal can give
+)
+
+// Errors returned by Play.
+var (
+	ErrNotYourTurn = errors.New("Not your turn")
+	ErrInvalidCard = errors.New("Card cannot be played")
+	ErrGameOver    = errors.New("The game is over")
+)
+
+// Move describes what happened after a card has been played.
+// TrickWinner is Nobody if the trick is not complete yet and
+// DealWinner is Nobody if the deal goes on.
+type Move struct {
+	Card        deck.Card
+	Marriage    int
+	TrickWinner int
+	TrickPoints int
+	DealWinner  int
+	DealPoints  int
+}
+
+// Game contains info about the deck and the current deal.
+type Game struct {
+	deck      *deck.Deck
+	gameScore [2]int
+
+	hands          [2][]deck.Card
+	trump          deck.Card
+	closedBy       int
+	trick          [2]deck.Card
+	hasTrickWon    [2]bool
+	marriages      [2]int
+	emptyCardSlots [2]int
+	playerInTurn   int
+	dealScore      [2]int
+	target         int // the points which win the game if not WinningPoints
+
+	source rand.Source // decides the deals if not nil
+
+	deals    func(deal int) rand.Source // decides each deal on its own if not nil
+	dealsNum int                        // the number of the current deal
+}
+
+// New returns a game which is ready to be started.
+func New() *Game {
+	return &Game{closedBy: Nobody}
+}
+
+// NewWithSource returns a game whose deals are decided by src.
+func NewWithSource(src rand.Source) *Game {
+	return &Game{closedBy: Nobody, source: src}
+}
+
+// NewSeeded returns a game whose deals are decided by seed.
+// Two games with the same seed get the same cards in every deal.
+func NewSeeded(seed int64) *Game {
+	return NewWithSource(rand.NewSource(seed))
+}
+
+// NewWithDeals returns a game whose deals don't depend on each other.
+// The deck of each deal is shuffled from the ordered deck by the source
+// which sources returns for the number of the deal, starting from 1.
+func NewWithDeals(sources func(deal int) rand.Source) *Game {
+	return &Game{closedBy: Nobody, deals: sources}
+}
+
+// Start creates a deck and deals the first cards.
+func (g *Game) Start() {
+	if g.source != nil {
+		g.deck = deck.NewWithSource(g.source)
+	} else {
+		g.deck = deck.New()
+	}
+	g.gameScore[Player1] = 0
+	g.gameScore[Player2] = 0
+	g.playerInTurn = Player2
+	g.dealsNum = 0
+	g.newDeal()
+}
+
+// newDeal starts new deal and resets the old deal info.
+func (g *Game) newDeal() {
+	g.dealsNum++
+	if g.deals != nil {
+		g.deck = deck.NewWithSource(g.deals(g.dealsNum))
+	}
+	g.deck.Shuffle()
+	g.closedBy = Nobody
+	g.trick[Player1] = NoCard
+	g.trick[Player2] = NoCard
+	g.hasTrickWon[Player1] = false
+	g.hasTrickWon[Player2] = false
+	g.marriages[Player1] = 0
+	g.marriages[Player2] = 0
+	g.dealScore[Player1] = 0
+	g.dealScore[Player2] = 0
+	g.deal()
+}
+
+// deal deals the first cards as if g.playerNotInTurn() is the dealer.
+func (g *Game) deal() {
+	g.hands[Player1] = make([]deck.Card, 6)
+	g.hands[Player2] = make([]deck.Card, 6)
+	hands, _ := g.deck.DrawNcards(13)
+	copy(g.hands[g.playerInTurn][:3], hands[:3])
+	copy(g.hands[g.playerInTurn][3:], hands[6:9])
+	copy(g.hands[g.playerNotInTurn()][:3], hands[3:6])
+	copy(g.hands[g.playerNotInTurn()][3:], hands[9:12])
+	g.trump = hands[12]
+}
+
+// playerNotInTurn returns the player who is waiting.
+func (g *Game) playerNotInTurn() int {
+	return 1 - g.playerInTurn
+}
+
+// isTrump gets a card and checks if it is the same suit as the trump.
+func (g *Game) isTrump(card deck.Card) bool {
+	return IsTrump(card, g.trump)
+}
+
+// OpponentOf returns the opponent of the player given as argument.
+func OpponentOf(player int) int {
+	return 1 - player
+}
+
+// addMarriagePoints adds marriage points to player if he has won a trick.
+func (g *Game) addMarriagePoints(player int) {
+	if g.hasTrickWon[player] {
+		g.dealScore[player] += g.marriages[player]
+		g.marriages[player] = 0
+	}
+}
+
+// checkForMarriage returns true and the points made from a marriage if any.
+func (g *Game) checkForMarriage(player int, card deck.Card) (bool, int) {
+	pts := MarriagePoints(g.hands[player], card, g.trick[OpponentOf(player)], g.trump)
+	g.marriages[player] += pts
+	return pts != 0, pts
+}
+
+// isPossibleExchange returns true if nine-trump exchange is possible.
+func (g *Game) isPossibleExchange(player int) (bool, int) {
+	if g.trick[OpponentOf(player)] != NoCard || g.trump.Rank() == deck.Nine ||
+		!g.hasTrickWon[player] || g.IsClosed() || len(g.deck.Current) == 0 {
+		return false, -1
+	}
+
+	for idx, card := range g.hands[player] {
+		if g.isTrump(card) && card.Rank() == deck.Nine {
+			return true, idx
+		}
+	}
+	return false, -1
+}
+
+// hasSameSuit returns true if the player has a card from the same suit as the card given as argument.
+func (g *Game) hasSameSuit(player int, card deck.Card) bool {
+	return HasSameSuit(g.hands[player], card)
+}
+
+// hasSameSuitHigher returns true if the player has a card from the same suit but higher rank than the card given.
+func (g *Game) hasSameSuitHigher(player int, card deck.Card) bool {
+	return HasSameSuitHigher(g.hands[player], card)
+}
+
+// hasTrump returns true if player has at least one trump card.
+func (g *Game) hasTrump(player int) bool {
+	return HasTrump(g.hands[player], g.trump)
+}
+
+// isGoodResponse checks if the player can respond with the given card.
+func (g *Game) isGoodResponse(player int, card deck.Card) bool {
+	strict := g.IsClosed() || len(g.deck.Current) == 0
+	return IsGoodResponse(g.hands[player], card, g.trick[OpponentOf(player)], g.trump, strict)
+}
+
+// findWinner returns the player who wins the current trick.
+func (g *Game) findWinner() int {
+	if g.isTrump(g.trick[Player1]) && !g.isTrump(g.trick[Player2]) {
+		return Player1
+	}
+	if g.isTrump(g.trick[Player2]) && !g.isTrump(g.trick[Player1]) {
+		return Player2
+	}
+	if deck.AreTheSameSuit(g.trick[Player1], g.trick[Player2]) {
+		if g.trick[Player1].Points() > g.trick[Player2].Points() {
+			return Player1
+		}
+		return Player2
+	}
+	return g.playerNotInTurn()
+}
+
+// trickPoints returns the points in the current trick.
+func (g *Game) trickPoints() int {
+	return g.trick[Player1].Points() + g.trick[Player2].Points()
+}
+
+// draw replenishes players' hands if deck is not empty or closed.
+func (g *Game) draw() {
+	secondToDraw := g.playerNotInTurn()
+	if len(g.deck.Current) == 0 || g.IsClosed() {
+		g.hands[Player1] = append(g.hands[Player1][:g.emptyCardSlots[Player1]], g.hands[Player1][g.emptyCardSlots[Player1]+1:]...)
+		g.hands[Player2] = append(g.hands[Player2][:g.emptyCardSlots[Player2]], g.hands[Player2][g.emptyCardSlots[Player2]+1:]...)
+	} else if len(g.deck.Current) == 1 {
+		g.hands[g.playerInTurn][g.emptyCardSlots[g.playerInTurn]], _ = g.deck.DrawCard()
+		g.hands[secondToDraw][g.emptyCardSlots[secondToDraw]] = g.trump
+	} else {
+		cards, _ := g.deck.DrawNcards(2)
+		g.hands[g.playerInTurn][g.emptyCardSlots[g.playerInTurn]] = cards[0]
+		g.hands[secondToDraw][g.emptyCardSlots[secondToDraw]] = cards[1]
+	}
+}
+
+// endDeal gives points to the winner and begins new deal if nobody has enough points to win the game.
+// It returns the winner and the points he has won.
+func (g *Game) endDeal(player int) (int, int) {
+	winner, pts := DealResult(player, g.closedBy, g.dealScore, g.hasTrickWon)
+	g.award(winner, pts)
+	return winner, pts
+}
+
+// award gives pts to the winner of the deal and begins new deal if he hasn't won the game.
+func (g *Game) award(winner, pts int) {
+	g.gameScore[winner] += pts
+	if g.gameScore[winner] < g.Target() {
+		g.playerInTurn = OpponentOf(winner)
+		g.newDeal()
+	}
+}
+
+// isCardValid returns true if player can respond with cardIdx.
+func (g *Game) isCardValid(player, cardIdx int) bool {
+	if cardIdx < 0 || len(g.hands[player]) <= cardIdx ||
+		(g.trick[OpponentOf(player)] != NoCard && !g.isGoodResponse(player, g.hands[player][cardIdx])) {
+		return false
+	}
+	return true
+}
+
+// playerPlayed puts the card on the table and returns it.
+func (g *Game) playerPlayed(player, cardIdx int) deck.Card {
+	card := g.hands[player][cardIdx]
+	g.trick[player] = card
+	g.hands[player][cardIdx] = NoCard
+	g.emptyCardSlots[player] = cardIdx
+	return card
+}
+
+// Play puts the card with index cardIdx from player's hand on the table.
+// If this completes the trick, the trick is scored, the players draw and
+// the deal is ended when the hands are empty.
+func (g *Game) Play(player, cardIdx int) (Move, error) {
+	move := Move{TrickWinner: Nobody, DealWinner: Nobody}
+	if g.IsOver() {
+		return move, ErrGameOver
+	}
+	if player != g.playerInTurn {
+		return move, ErrNotYourTurn
+	}
+	if !g.isCardValid(player, cardIdx) {
+		return move, ErrInvalidCard
+	}
+
+	move.Card = g.playerPlayed(player, cardIdx)
+	if hasMarriage, pts := g.checkForMarriage(player, move.Card); hasMarriage {
+		g.addMarriagePoints(player)
+		move.Marriage = pts
+	}
+
+	if g.trick[OpponentOf(player)] == NoCard {
+		g.playerInTurn = g.playerNotInTurn()
+		return move, nil
+	}
+
+	winner := g.findWinner()
+	g.playerInTurn = winner
+	g.hasTrickWon[winner] = true
+
+	g.addMarriagePoints(winner)
+	move.TrickWinner = winner
+	move.TrickPoints = g.trickPoints()
+	g.dealScore[winner] += move.TrickPoints
+
+	g.trick[Player1] = NoCard
+	g.trick[Player2] = NoCard
+
+	g.draw()
+	if len(g.hands[player]) == 0 {
+		if !g.IsClosed() {
+			g.dealScore[g.playerInTurn] += LastTrickBonus
+		}
+		move.DealWinner, move.DealPoints = g.endDeal(Nobody)
+	}
+	return move, nil
+}
+
+// Close changes the deal to closed by player if possible and returns if succeeded.
+func (g *Game) Close(player int) bool {
+	if !g.CanClose(player) {
+		return false
+	}
+	g.closedBy = player
+	return true
+}
+
+// Exchange makes nine-trump exchange if possible and returns if it succeeded.
+func (g *Game) Exchange(player int) bool {
+	if g.IsOver() || player != g.playerInTurn {
+		return false
+	}
+	if ok, idx := g.isPossibleExchange(player); ok {
+		g.hands[player][idx], g.trump = g.trump, g.hands[player][idx]
+		return true
+	}
+	return false
+}
+
+// Stop ends the current deal and finds the winner and the points if possible.
+// It returns true if succeeded and winner and points.
+func (g *Game) Stop(player int) (bool, int, int) {
+	if !g.CanStop(player) {
+		return false, Nobody, 0
+	}
+	winner, pts := g.endDeal(player)
+	return true, winner, pts
+}
+
+// ForfeitDeal ends the current deal as lost by player, whose opponent wins ForfeitPoints.
+// It returns the winner and the points or Nobody if the game is over.
+func (g *Game) ForfeitDeal(player int) (int, int) {
+	if g.IsOver() {
+		return Nobody, 0
+	}
+	winner := OpponentOf(player)
+	g.award(winner, ForfeitPoints)
+	return winner, ForfeitPoints
+}
+
+// ForfeitGame ends the game as lost by player. His opponent gets the points
+// which win the game. It returns the winner of the game.
+func (g *Game) ForfeitGame(player int) int {
+	if !g.IsOver() {
+		g.gameScore[OpponentOf(player)] = g.Target()
+	}
+	return g.Winner()
+}
+
+// LowestCard returns the index of the card with the fewest points player can play now,
+// one which isn't a trump if possible, or -1 if he cannot play.
+func (g *Game) LowestCard(player int) int {
+	lowest := -1
+	for _, idx := range g.LegalCards(player) {
+		card := g.hands[player][idx]
+		if lowest == -1 {
+			lowest = idx
+			continue
+		}
+		best := g.hands[player][lowest]
+		if g.isTrump(best) != g.isTrump(card) {
+			if g.isTrump(best) {
+				lowest = idx
+			}
+		} else if card.Points() < best.Points() {
+			lowest = idx
+		}
+	}
+	return lowest
+}
+
+// CanPlay returns true if player is in turn and can play the card with index cardIdx.
+func (g *Game) CanPlay(player, cardIdx int) bool {
+	return !g.IsOver() && player == g.playerInTurn && g.isCardValid(player, cardIdx)
+}
+
+// LegalCards returns the indexes of the cards player can play now.
+func (g *Game) LegalCards(player int) []int {
+	var idxs []int
+	for idx := range g.hands[player] {
+		if g.CanPlay(player, idx) {
+			idxs = append(idxs, idx)
+		}
+	}
+	return idxs
+}
+
+// CanClose returns true if player can close the deck now.
+func (g *Game) CanClose(player int) bool {
+	return !g.IsOver() && player == g.playerInTurn && !g.IsClosed() &&
+		len(g.deck.Current) != 0 && g.trick[OpponentOf(player)] == NoCard
+}
+
+// CanExchange returns true if player can exchange the nine of trumps now.
+func (g *Game) CanExchange(player int) bool {
+	ok, _ := g.isPossibleExchange(player)
+	return ok && !g.IsOver() && player == g.playerInTurn
+}
+
+// CanStop returns true if player can stop the deal now.
+func (g *Game) CanStop(player int) bool {
+	return !g.IsOver() && player == g.playerInTurn && g.trick[OpponentOf(player)] == NoCard
+}
+
+// Hand returns a copy of player's hand.
+func (g *Game) Hand(player int) []deck.Card {
+	hand := make([]deck.Card, len(g.hands[player]))
+	copy(hand, g.hands[player])
+	return hand
+}
+
+// Trump returns the card which determines the trump suit.
+func (g *Game) Trump() deck.Card {
+	return g.trump
+}
+
+// TalonSize returns the number of cards left in the deck without the trump.
+func (g *Game) TalonSize() int {
+	return len(g.deck.Current)
+}
+
+// IsClosed returns true if the deck is closed.
+func (g *Game) IsClosed() bool {
+	return g.closedBy != Nobody
+}
+
+// ClosedBy returns the player who closed the deck or Nobody.
+func (g *Game) ClosedBy() int {
+	return g.closedBy
+}
+
+// Table returns the card player has put on the table in the current trick or NoCard.
+func (g *Game) Table(player int) deck.Card {
+	return g.trick[player]
+}
+
+// PlayerInTurn returns the player who has to act now.
+func (g *Game) PlayerInTurn() int {
+	return g.playerInTurn
+}
+
+// HasWonTrick returns true if player has won at least one trick in the current deal.
+func (g *Game) HasWonTrick(player int) bool {
+	return g.hasTrickWon[player]
+}
+
+// DealScore returns the points player has in the current deal.
+func (g *Game) DealScore(player int) int {
+	return g.dealScore[player]
+}
+
+// GameScore returns the game points of player.
+func (g *Game) GameScore(player int) int {
+	return g.gameScore[player]
+}
+
+// SetTarget sets the points which win the game. Zero is WinningPoints.
+func (g *Game) SetTarget(points int) {
+	g.target = points
+}
+
+// Target returns the points which win the game.
+func (g *Game) Target() int {
+	if g.target <= 0 {
+		return WinningPoints
+	}
+	return g.target
+}
+
+// IsOver returns true if one of the players has enough points to win the game.
+func (g *Game) IsOver() bool {
+	return g.Winner() != Nobody
+}
+
+// Winner returns the player who has won the game or Nobody.
+func (g *Game) Winner() int {
+	for _, player := range [2]int{Player1, Player2} {
+		if g.gameScore[player] >= g.Target() {
+			return player
+		}
+	}
+	return Nobody
+}
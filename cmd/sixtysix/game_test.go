@@ -0,0 +1,293 @@
+package sixtysix
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// card and cards return the cards written in the tests.
+var card = deck.MustParse
+
+func cards(s string) []deck.Card {
+	c, err := deck.ParseCards(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+var (
+	test  = New()
+	hand  = cards("Q♥ 9♥ K♥ X♠")
+	trump = card("A♥")
+)
+
+func TestStart(t *testing.T) {
+	test.Start()
+
+	if len(test.hands[Player1]) != len(test.hands[Player2]) || len(test.hands[Player1]) != 6 {
+		t.Error("Hands' size is wrong!")
+	}
+
+	for player := 0; player < 2; player++ {
+		for _, card := range test.hands[player] {
+			if card == test.trump {
+				t.Error("Nobody should have the trump from the beginning!")
+			}
+		}
+	}
+
+	if len(test.deck.Current)+1 != 12 {
+		t.Error("Deck size not right!")
+	}
+
+	if test.playerInTurn != Player2 || test.playerNotInTurn() != Player1 {
+		t.Error("Not the right player in turn!")
+	}
+}
+
+func TestNewSeeded(t *testing.T) {
+	g1, g2 := NewSeeded(66), NewSeeded(66)
+	g1.Start()
+	g2.Start()
+	for deal := 0; deal < 2; deal++ {
+		if g1.Trump() != g2.Trump() || fmt.Sprint(g1.Hand(Player1)) != fmt.Sprint(g2.Hand(Player1)) {
+			t.Fatal("Seeded games differ!")
+		}
+		g1.Stop(g1.PlayerInTurn())
+		g2.Stop(g2.PlayerInTurn())
+	}
+}
+
+func TestNewWithDeals(t *testing.T) {
+	same := func(int) rand.Source { return rand.NewSource(66) }
+	g := NewWithDeals(same)
+	g.Start()
+	trump, hand := g.Trump(), fmt.Sprint(g.Hand(g.PlayerInTurn()))
+	g.Stop(g.PlayerInTurn())
+	if g.Trump() != trump || fmt.Sprint(g.Hand(g.PlayerInTurn())) != hand {
+		t.Error("Deals with the same source differ!")
+	}
+}
+
+func TestCheckForMarriage(t *testing.T) {
+	test.hands[Player1] = hand
+	test.trump = trump
+
+	if ok, pts := test.checkForMarriage(Player1, card("K♥")); !ok || pts != 40 {
+		t.Error("Marriage error!")
+	}
+
+	if ok, pts := test.checkForMarriage(Player1, card("9♥")); ok || pts != 0 {
+		t.Error("Marriage error!")
+	}
+}
+
+func TestIsPossibleExchange(t *testing.T) {
+	test.playerInTurn = Player2
+	test.trick[Player1] = NoCard
+	test.hands[Player2] = hand
+	test.trump = trump
+
+	if ok, _ := test.isPossibleExchange(Player2); ok {
+		t.Error("Exchange error!")
+	}
+
+	test.hasTrickWon[Player2] = true
+	if ok, _ := test.isPossibleExchange(Player2); !ok {
+		t.Error("Exchange error!")
+	}
+
+	test.hands[Player2][1] = card("J♠")
+	if ok, _ := test.isPossibleExchange(Player2); ok {
+		t.Error("Exchange error!")
+	}
+}
+
+func TestHasSameSuit(t *testing.T) {
+	test.hands[Player1] = hand
+	if !test.hasSameSuit(Player1, card("J♥")) {
+		t.Error("Same suit error!")
+	}
+	if test.hasSameSuit(Player1, card("J♦")) {
+		t.Error("Same suit error!")
+	}
+}
+
+func TestHasSameSuitHigher(t *testing.T) {
+	test.hands[Player1] = hand
+	if !test.hasSameSuitHigher(Player1, card("J♥")) {
+		t.Error("Same suit error!")
+	}
+	if test.hasSameSuitHigher(Player1, card("J♦")) {
+		t.Error("Same suit error!")
+	}
+}
+
+func TestHasTrump(t *testing.T) {
+	test.hands[Player1] = hand
+	if !test.hasTrump(Player1) {
+		t.Error("Trump error!")
+	}
+
+	test.trump = card("J♦")
+	if test.hasTrump(Player1) {
+		t.Error("Trump error!")
+	}
+}
+
+func TestFindWinner(t *testing.T) {
+	test.playerInTurn = Player2
+	test.trump = card("J♠")
+	test.trick[Player1] = card("J♥")
+	test.trick[Player2] = card("J♦")
+	if test.findWinner() != Player1 {
+		t.Error("Trick win error!")
+	}
+
+	test.trump = card("Q♦")
+	if test.findWinner() != Player2 {
+		t.Error("Trick win error!")
+	}
+
+	test.trick[Player2] = card("Q♥")
+	if test.findWinner() != Player2 {
+		t.Error("Trick win error!")
+	}
+}
+
+func TestEndDeal(t *testing.T) {
+	test.dealScore[Player1] = 67
+	test.dealScore[Player2] = 47
+	test.hasTrickWon[Player1] = true
+	test.hasTrickWon[Player2] = true
+	test.closedBy = Nobody
+
+	if player, pts := test.endDeal(Nobody); player != Player1 || pts != 1 {
+		t.Error("End deal error!")
+	}
+
+	test.dealScore[Player1] = 65
+	test.dealScore[Player2] = 47
+	test.hasTrickWon[Player1] = true
+	test.hasTrickWon[Player2] = true
+	if player, pts := test.endDeal(Player1); player != Player2 || pts != 2 {
+		t.Error("End deal error!")
+	}
+
+	test.dealScore[Player1] = 65
+	test.dealScore[Player2] = 0
+	test.hasTrickWon[Player1] = true
+	if player, pts := test.endDeal(Nobody); player != Player1 || pts != 3 {
+		t.Error("End deal error!")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	copy(test.hands[Player1], hand)
+	copy(test.hands[Player2], hand)
+	test.emptyCardSlots[Player1] = 4
+	test.emptyCardSlots[Player2] = 4
+
+	test.deck.Shuffle()
+	test.draw()
+	if len(test.hands[Player1]) != len(test.hands[Player2]) ||
+		len(test.deck.Current) != 22 || test.hands[Player1][4] == test.hands[Player2][4] {
+		t.Error("Eror in drawing.")
+	}
+
+	test.trump = test.deck.Current[0]
+	test.deck.Current = test.deck.Current[21:]
+	test.playerInTurn = Player1
+	test.emptyCardSlots[Player1] = 5
+	test.emptyCardSlots[Player2] = 5
+	test.draw()
+	if len(test.deck.Current) != 0 || test.hands[Player2][5] != test.trump {
+		t.Error("Eror in drawing.")
+	}
+
+	test.draw()
+	if len(test.deck.Current) != 0 || len(test.hands[Player2]) != 5 {
+		t.Error("Eror in drawing.")
+	}
+}
+
+func TestPlay(t *testing.T) {
+	g := New()
+	g.Start()
+
+	if _, err := g.Play(Player1, 0); err != ErrNotYourTurn {
+		t.Error("Play error!")
+	}
+	if _, err := g.Play(Player2, 6); err != ErrInvalidCard {
+		t.Error("Play error!")
+	}
+
+	move, err := g.Play(Player2, 0)
+	if err != nil || move.TrickWinner != Nobody || g.Table(Player2) != move.Card || g.PlayerInTurn() != Player1 {
+		t.Error("Play error!")
+	}
+
+	move, err = g.Play(Player1, g.LegalCards(Player1)[0])
+	if err != nil || move.TrickWinner == Nobody || g.PlayerInTurn() != move.TrickWinner ||
+		g.DealScore(move.TrickWinner) < move.TrickPoints || len(g.Hand(Player1)) != 6 || g.TalonSize() != 9 {
+		t.Error("Play error!")
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	g := NewSeeded(3)
+	g.Start()
+	if winner, pts := g.ForfeitDeal(Player1); winner != Player2 || pts != ForfeitPoints || g.GameScore(Player2) != ForfeitPoints {
+		t.Error("ForfeitDeal error!", winner, pts)
+	}
+	if g.TalonSize() != 11 || g.PlayerInTurn() != Player1 {
+		t.Error("ForfeitDeal didn't start a new deal!", g.TalonSize(), g.PlayerInTurn())
+	}
+
+	if winner := g.ForfeitGame(Player2); winner != Player1 || !g.IsOver() || g.GameScore(Player1) != WinningPoints {
+		t.Error("ForfeitGame error!", winner)
+	}
+	if winner, _ := g.ForfeitDeal(Player1); winner != Nobody {
+		t.Error("Forfeit after the game is over!")
+	}
+}
+
+func TestLowestCard(t *testing.T) {
+	g := Restore(State{
+		Hands:        [2][]deck.Card{cards("A♠ 9♥ J♣ X♣"), cards("K♠ Q♠ 9♠ X♦")},
+		Trump:        card("Q♥"),
+		ClosedBy:     Nobody,
+		PlayerInTurn: Player1,
+	})
+	if idx := g.LowestCard(Player1); idx != 2 {
+		t.Error("LowestCard error: the nine of trumps is worth keeping!", idx)
+	}
+
+	g.Play(Player1, 0)
+	if idx := g.LowestCard(Player2); idx != 2 {
+		t.Error("LowestCard error!", idx)
+	}
+	if idx := g.LowestCard(Player1); idx != -1 {
+		t.Error("LowestCard error: not in turn!", idx)
+	}
+}
+
+func TestTarget(t *testing.T) {
+	g := NewSeeded(3)
+	g.SetTarget(ForfeitPoints)
+	g.Start()
+	g.ForfeitDeal(Player1)
+	if !g.IsOver() || g.Winner() != Player2 {
+		t.Error("Target error!", g.GameScore(Player2))
+	}
+	if restored := Restore(g.State()); restored.Target() != ForfeitPoints || !restored.IsOver() {
+		t.Error("Target isn't restored!", restored.Target())
+	}
+	if New().Target() != WinningPoints {
+		t.Error("Default target error!")
+	}
+}
@@ -0,0 +1,118 @@
+package sixtysix
+
+import "github.com/DanislavKirov/sixtySix/cmd/deck"
+
+// IsTrump returns true if card is from the same suit as trump.
+func IsTrump(card, trump deck.Card) bool {
+	return deck.AreTheSameSuit(card, trump)
+}
+
+// Beats returns true if card wins against led, which was played first.
+func Beats(card, led, trump deck.Card) bool {
+	return (IsTrump(card, trump) && !IsTrump(led, trump)) ||
+		(deck.AreTheSameSuit(card, led) && deck.HasHigherRank(card, led))
+}
+
+// HasSameSuit returns true if hand has a card from the same suit as card.
+func HasSameSuit(hand []deck.Card, card deck.Card) bool {
+	for _, c := range hand {
+		if c != NoCard && deck.AreTheSameSuit(c, card) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSameSuitHigher returns true if hand has a card from the same suit but higher rank than card.
+func HasSameSuitHigher(hand []deck.Card, card deck.Card) bool {
+	for _, c := range hand {
+		if c != NoCard && deck.AreTheSameSuit(c, card) && deck.HasHigherRank(c, card) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTrump returns true if hand has at least one trump card.
+func HasTrump(hand []deck.Card, trump deck.Card) bool {
+	return HasSameSuit(hand, trump)
+}
+
+// IsGoodResponse returns true if card from hand can be played against led.
+// Strict is true when the deck is closed or empty, then the player has to
+// follow suit, beat the led card if he can or play a trump.
+func IsGoodResponse(hand []deck.Card, card, led, trump deck.Card, strict bool) bool {
+	if !strict {
+		return true
+	}
+	if !deck.AreTheSameSuit(card, led) &&
+		(HasSameSuit(hand, led) || (!IsTrump(led, trump) && !IsTrump(card, trump) && HasTrump(hand, trump))) {
+		return false
+	}
+	if deck.AreTheSameSuit(card, led) && !deck.HasHigherRank(card, led) && HasSameSuitHigher(hand, led) {
+		return false
+	}
+	return true
+}
+
+// MarriagePoints returns the points of the marriage announced by playing card
+// from hand against led (NoCard if the player leads). It returns 0 if there is no marriage.
+func MarriagePoints(hand []deck.Card, card, led, trump deck.Card) int {
+	if (card.Rank() != deck.Queen && card.Rank() != deck.King) ||
+		(led != NoCard && !deck.AreTheSameSuit(led, card) && !IsTrump(card, trump)) {
+		return 0
+	}
+
+	partner := deck.NewCard(deck.Queen, card.Suit())
+	if card.Rank() == deck.Queen {
+		partner = deck.NewCard(deck.King, card.Suit())
+	}
+
+	for _, c := range hand {
+		if c == partner {
+			if IsTrump(card, trump) {
+				return 40
+			}
+			return 20
+		}
+	}
+	return 0
+}
+
+// dealWinPointsAgainst returns the points won against player.
+func dealWinPointsAgainst(player int, score [2]int, hasTrickWon [2]bool) int {
+	if !hasTrickWon[player] {
+		return 3
+	}
+	if score[player] < 33 {
+		return 2
+	}
+	return 1
+}
+
+// dealWinnerAndPoints returns the winner of the deal and the points if player has stopped or closed it.
+func dealWinnerAndPoints(player int, score [2]int, hasTrickWon [2]bool) (int, int) {
+	opponent := OpponentOf(player)
+	if !hasTrickWon[player] {
+		return opponent, 3
+	}
+	if score[player] >= WinningScore && score[player] > score[opponent] {
+		return player, dealWinPointsAgainst(opponent, score, hasTrickWon)
+	}
+	return opponent, 2
+}
+
+// DealResult returns the winner of the deal and the points he wins.
+// Player is the one who has stopped the deal or Nobody if the last trick has been played.
+func DealResult(player, closedBy int, score [2]int, hasTrickWon [2]bool) (int, int) {
+	if player != Nobody {
+		return dealWinnerAndPoints(player, score, hasTrickWon)
+	}
+	if closedBy != Nobody {
+		return dealWinnerAndPoints(closedBy, score, hasTrickWon)
+	}
+	if score[Player1] > score[Player2] {
+		return Player1, dealWinPointsAgainst(Player2, score, hasTrickWon)
+	}
+	return Player2, dealWinPointsAgainst(Player1, score, hasTrickWon)
+}
@@ -0,0 +1,26 @@
+package sixtysix
+
+import "testing"
+
+func TestBeats(t *testing.T) {
+	if !Beats(card("9♥"), card("A♠"), card("K♥")) || Beats(card("A♠"), card("9♥"), card("K♥")) ||
+		!Beats(card("X♠"), card("K♠"), card("K♥")) || Beats(card("A♦"), card("9♠"), card("K♥")) {
+		t.Error("Beats error!")
+	}
+}
+
+func TestIsGoodResponse(t *testing.T) {
+	hand := cards("Q♥ 9♥ K♠ X♠")
+	if !IsGoodResponse(hand, card("Q♥"), card("J♠"), card("A♥"), false) {
+		t.Error("Response error!")
+	}
+	if IsGoodResponse(hand, card("Q♥"), card("J♠"), card("A♥"), true) || IsGoodResponse(cards("J♠ X♠"), card("J♠"), card("Q♠"), card("A♥"), true) {
+		t.Error("Response error!")
+	}
+	if !IsGoodResponse(hand, card("X♠"), card("Q♠"), card("A♥"), true) || !IsGoodResponse(hand, card("9♥"), card("J♦"), card("A♥"), true) {
+		t.Error("Response error!")
+	}
+	if IsGoodResponse(hand, card("K♠"), card("J♦"), card("A♥"), true) {
+		t.Error("Response error!")
+	}
+}
@@ -0,0 +1,67 @@
+package sixtysix
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// SaveVersion is the version of the games written by MarshalState.
+// UnmarshalState reads this and all older versions.
+const SaveVersion = 1
+
+// Errors returned by UnmarshalState.
+var (
+	ErrSaveVersion = errors.New("Unknown version of the saved game")
+	ErrBadSave     = errors.New("The saved game is broken")
+)
+
+// savedState is a state written with its version.
+type savedState struct {
+	Version int   `json:"version"`
+	State   State `json:"state"`
+}
+
+// MarshalState returns s written as JSON with SaveVersion, so that it can be
+// read by UnmarshalState after the program restarts.
+func MarshalState(s State) ([]byte, error) {
+	return json.Marshal(savedState{Version: SaveVersion, State: s})
+}
+
+// UnmarshalState reads a state written by MarshalState.
+func UnmarshalState(data []byte) (State, error) {
+	var saved savedState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return State{}, ErrBadSave
+	}
+	if saved.Version < 1 || saved.Version > SaveVersion {
+		return State{}, ErrSaveVersion
+	}
+	if !isValid(saved.State) {
+		return State{}, ErrBadSave
+	}
+	return saved.State, nil
+}
+
+// isValid returns true if every card of s is in the game at most once
+// and the players are real.
+func isValid(s State) bool {
+	if s.PlayerInTurn != Player1 && s.PlayerInTurn != Player2 ||
+		s.ClosedBy < Player1 || s.ClosedBy > Nobody || !s.Trump.IsValid() {
+		return false
+	}
+
+	seen := map[deck.Card]bool{s.Trump: true}
+	all := append(append(append([]deck.Card(nil), s.Talon...), s.Hands[Player1]...), s.Hands[Player2]...)
+	for _, card := range append(all, s.Trick[Player1], s.Trick[Player2]) {
+		if card == NoCard {
+			continue
+		}
+		if !card.IsValid() || seen[card] {
+			return false
+		}
+		seen[card] = true
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+package sixtysix
+
+import (
+	"errors"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// ErrNotEndgame is returned when a position cannot be solved because the deck is open.
+var ErrNotEndgame = errors.New("The deck is neither closed nor empty")
+
+// Endgame is a position after the deck has been closed or emptied in which
+// both hands are known. The player in Turn has to act. If Table is not NoCard
+// it has been led by his opponent and is not in his opponent's hand anymore.
+// Marriages are announced but not counted yet because the player hasn't won a trick.
+type Endgame struct {
+	Hands       [2][]deck.Card
+	Trump       deck.Card
+	Table       deck.Card
+	Turn        int
+	ClosedBy    int
+	Score       [2]int
+	Marriages   [2]int
+	HasTrickWon [2]bool
+}
+
+// Solution is the best play for the player in turn and what happens if both
+// players play perfectly after it.
+type Solution struct {
+	Card   deck.Card // NoCard if the best is to stop the deal
+	Stop   bool
+	Winner int
+	Points int    // the game points the winner gets
+	Score  [2]int // the deal points at the end of the deal
+}
+
+// Endgame returns the current position if the deck is closed or empty.
+func (g *Game) Endgame() (Endgame, error) {
+	if !g.IsClosed() && len(g.deck.Current) != 0 {
+		return Endgame{}, ErrNotEndgame
+	}
+
+	e := Endgame{
+		Trump:       g.trump,
+		Table:       g.trick[g.playerNotInTurn()],
+		Turn:        g.playerInTurn,
+		ClosedBy:    g.closedBy,
+		Score:       g.dealScore,
+		Marriages:   g.marriages,
+		HasTrickWon: g.hasTrickWon,
+	}
+	for player := range e.Hands {
+		for _, card := range g.hands[player] {
+			if card != NoCard {
+				e.Hands[player] = append(e.Hands[player], card)
+			}
+		}
+	}
+	return e, nil
+}
+
+// Solve returns the best play in the current position if the deck is closed or empty.
+// It uses the hand of the opponent, so it must not be shown to a player before
+// the deck is empty.
+func (g *Game) Solve() (Solution, error) {
+	e, err := g.Endgame()
+	if err != nil {
+		return Solution{}, err
+	}
+	return e.Solve(), nil
+}
+
+// outcome is the end of a deal reached by the search.
+type outcome struct {
+	value  int // for the player who started the search
+	winner int
+	points int
+	score  [2]int
+}
+
+// valueScale makes the game points more important than the deal points in values.
+const valueScale = 1000
+
+// Solve finds the best play with alpha-beta search over all remaining cards.
+// The player wants to win as many game points as possible and then to have
+// as many deal points more than his opponent as possible.
+func (e Endgame) Solve() Solution {
+	s := &search{e: e, me: e.Turn}
+	s.e.Hands[Player1] = append([]deck.Card(nil), e.Hands[Player1]...)
+	s.e.Hands[Player2] = append([]deck.Card(nil), e.Hands[Player2]...)
+
+	best := outcome{value: -valueScale * 10}
+	var solution Solution
+	alpha, beta := -valueScale*10, valueScale*10
+
+	if s.e.Table == NoCard {
+		o := s.stop()
+		best, solution = o, Solution{Stop: true}
+		alpha = o.value
+	}
+
+	hand := s.e.Hands[s.e.Turn]
+	for _, idx := range s.moves() {
+		card := hand[idx]
+		o := s.play(idx, alpha, beta)
+		if o.value > best.value {
+			best, solution = o, Solution{Card: card}
+		}
+		if o.value > alpha {
+			alpha = o.value
+		}
+	}
+
+	solution.Winner, solution.Points, solution.Score = best.winner, best.points, best.score
+	return solution
+}
+
+// search keeps the position while it is being searched.
+type search struct {
+	e  Endgame
+	me int
+}
+
+// result returns the outcome if the deal is ended by player (Nobody if the hands are empty).
+func (s *search) result(player int) outcome {
+	winner, pts := DealResult(player, s.e.ClosedBy, s.e.Score, s.e.HasTrickWon)
+	o := outcome{winner: winner, points: pts, score: s.e.Score}
+	o.value = pts*valueScale + s.e.Score[winner] - s.e.Score[OpponentOf(winner)]
+	if winner != s.me {
+		o.value = -o.value
+	}
+	return o
+}
+
+// stop returns the outcome if the player in turn stops the deal.
+func (s *search) stop() outcome {
+	return s.result(s.e.Turn)
+}
+
+// moves returns the indexes of the cards the player in turn can play, the strongest first.
+func (s *search) moves() []int {
+	hand := s.e.Hands[s.e.Turn]
+	idxs := make([]int, 0, len(hand))
+	for idx, card := range hand {
+		if s.e.Table == NoCard || IsGoodResponse(hand, card, s.e.Table, s.e.Trump, true) {
+			idxs = append(idxs, idx)
+		}
+	}
+
+	for i := 1; i < len(idxs); i++ {
+		for j := i; j > 0 && s.strength(hand[idxs[j]]) > s.strength(hand[idxs[j-1]]); j-- {
+			idxs[j], idxs[j-1] = idxs[j-1], idxs[j]
+		}
+	}
+	return idxs
+}
+
+// strength orders the cards for the search: trumps first, then by points.
+func (s *search) strength(card deck.Card) int {
+	if IsTrump(card, s.e.Trump) {
+		return 100 + card.Points()
+	}
+	return card.Points()
+}
+
+// addMarriagePoints counts the announced marriages of player if he has won a trick.
+func (s *search) addMarriagePoints(player int) {
+	if s.e.HasTrickWon[player] {
+		s.e.Score[player] += s.e.Marriages[player]
+		s.e.Marriages[player] = 0
+	}
+}
+
+// play plays the card with index idx of the player in turn and searches what follows.
+func (s *search) play(idx, alpha, beta int) outcome {
+	saved := s.e
+	player := s.e.Turn
+	hand := s.e.Hands[player]
+	card := hand[idx]
+
+	last := len(hand) - 1
+	hand[idx], hand[last] = hand[last], hand[idx]
+	s.e.Hands[player] = hand[:last]
+	defer func() {
+		hand[idx], hand[last] = hand[last], hand[idx]
+		s.e = saved
+	}()
+
+	if pts := MarriagePoints(s.e.Hands[player], card, s.e.Table, s.e.Trump); pts != 0 {
+		s.e.Marriages[player] += pts
+		s.addMarriagePoints(player)
+	}
+
+	if s.e.Table == NoCard {
+		s.e.Table = card
+		s.e.Turn = OpponentOf(player)
+		return s.alphaBeta(alpha, beta)
+	}
+
+	winner := OpponentOf(player)
+	if Beats(card, s.e.Table, s.e.Trump) {
+		winner = player
+	}
+	s.e.HasTrickWon[winner] = true
+	s.addMarriagePoints(winner)
+	s.e.Score[winner] += card.Points() + s.e.Table.Points()
+	s.e.Table = NoCard
+	s.e.Turn = winner
+
+	if len(s.e.Hands[player]) == 0 {
+		if s.e.ClosedBy == Nobody {
+			s.e.Score[winner] += LastTrickBonus
+		}
+		return s.result(Nobody)
+	}
+	return s.alphaBeta(alpha, beta)
+}
+
+// alphaBeta returns the outcome of the current position if both players play perfectly.
+func (s *search) alphaBeta(alpha, beta int) outcome {
+	maximize := s.e.Turn == s.me
+	var best outcome
+	first := true
+
+	if s.e.Table == NoCard {
+		best, first = s.stop(), false
+		if maximize && best.value > alpha {
+			alpha = best.value
+		} else if !maximize && best.value < beta {
+			beta = best.value
+		}
+		if alpha >= beta {
+			return best
+		}
+	}
+
+	for _, idx := range s.moves() {
+		o := s.play(idx, alpha, beta)
+		if first || (maximize && o.value > best.value) || (!maximize && o.value < best.value) {
+			best, first = o, false
+		}
+
+		if maximize && best.value > alpha {
+			alpha = best.value
+		} else if !maximize && best.value < beta {
+			beta = best.value
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
@@ -0,0 +1,137 @@
+package sixtysix
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// minimax returns the game points won by player (negative if lost) if both play perfectly.
+func minimax(g *Game, player int) int {
+	inTurn := g.PlayerInTurn()
+	best := -10
+	if inTurn != player {
+		best = 10
+	}
+	better := func(value int) {
+		if (inTurn == player && value > best) || (inTurn != player && value < best) {
+			best = value
+		}
+	}
+	value := func(winner, pts int) int {
+		if winner == player {
+			return pts
+		}
+		return -pts
+	}
+
+	if g.CanStop(inTurn) {
+		c := g.Clone()
+		_, winner, pts := c.Stop(inTurn)
+		better(value(winner, pts))
+	}
+	for _, idx := range g.LegalCards(inTurn) {
+		c := g.Clone()
+		move, _ := c.Play(inTurn, idx)
+		if move.DealWinner != Nobody {
+			better(value(move.DealWinner, move.DealPoints))
+		} else {
+			better(minimax(c, player))
+		}
+	}
+	return best
+}
+
+// endgame plays random cards until the deck is closed or empty and the hands are small enough.
+func endgame(r *rand.Rand, size int) *Game {
+	for {
+		g := New()
+		g.Start()
+		for !g.IsOver() {
+			player := g.PlayerInTurn()
+			if g.CanClose(player) && r.Intn(8) == 0 {
+				g.Close(player)
+			}
+			if _, err := g.Endgame(); err == nil && len(g.Hand(player)) <= size {
+				return g
+			}
+
+			cards := g.LegalCards(player)
+			if move, _ := g.Play(player, cards[r.Intn(len(cards))]); move.DealWinner != Nobody {
+				break
+			}
+		}
+	}
+}
+
+func TestSolveMatchesMinimax(t *testing.T) {
+	r := rand.New(rand.NewSource(66))
+	for i := 0; i < 30; i++ {
+		g := endgame(r, 4)
+		player := g.PlayerInTurn()
+		solution, err := g.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := solution.Points
+		if solution.Winner != player {
+			got = -got
+		}
+		if want := minimax(g, player); got != want {
+			t.Error("Solve error!", got, want)
+		}
+
+		c := g.Clone()
+		if solution.Stop {
+			if !c.CanStop(player) {
+				t.Error("Solve error: cannot stop!")
+			}
+			continue
+		}
+		idx := -1
+		for i, card := range c.Hand(player) {
+			if card == solution.Card {
+				idx = i
+			}
+		}
+		if !c.CanPlay(player, idx) {
+			t.Error("Solve error: illegal card!", solution.Card)
+		}
+	}
+}
+
+func TestSolveFullHands(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	g := endgame(r, 6)
+	if _, err := g.Solve(); err != nil {
+		t.Error(err)
+	}
+
+	g = New()
+	g.Start()
+	if _, err := g.Solve(); err != ErrNotEndgame {
+		t.Error("Expected not endgame!")
+	}
+}
+
+func TestSolveStop(t *testing.T) {
+	e := Endgame{
+		Hands:       [2][]deck.Card{cards("9♠ J♠"), cards("A♠ X♠")},
+		Trump:       card("Q♥"),
+		Turn:        Player1,
+		ClosedBy:    Player1,
+		Score:       [2]int{66, 40},
+		HasTrickWon: [2]bool{true, true},
+	}
+	if s := e.Solve(); !s.Stop || s.Winner != Player1 || s.Points != 1 {
+		t.Error("Expected stop!", s)
+	}
+
+	e.Hands = [2][]deck.Card{cards("A♠ X♠"), cards("9♠ J♠")}
+	e.Score = [2]int{50, 40}
+	if s := e.Solve(); s.Stop || s.Card != card("A♠") || s.Winner != Player1 || s.Points != 1 {
+		t.Error("Solve error!", s)
+	}
+}
@@ -0,0 +1,96 @@
+package sixtysix
+
+import (
+	"math/rand"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+// State is everything a game consists of. A game can be restored from it,
+// which lets players try moves on a copy of a game or on a game they imagine.
+// The hand of a player who has put a card on the table has NoCard at
+// EmptyCardSlots[player].
+type State struct {
+	Talon          []deck.Card    `json:"talon"` // the cards left in the deck without the trump, the next one first
+	GameScore      [2]int         `json:"gameScore"`
+	Hands          [2][]deck.Card `json:"hands"`
+	Trump          deck.Card      `json:"trump"`
+	ClosedBy       int            `json:"closedBy"`
+	Trick          [2]deck.Card   `json:"trick"`
+	HasTrickWon    [2]bool        `json:"hasTrickWon"`
+	Marriages      [2]int         `json:"marriages"` // announced but not counted because the player hasn't won a trick
+	EmptyCardSlots [2]int         `json:"emptyCardSlots"`
+	PlayerInTurn   int            `json:"playerInTurn"`
+	DealScore      [2]int         `json:"dealScore"`
+	Deal           int            `json:"deal"`             // the number of the deal, starting from 1
+	Target         int            `json:"target,omitempty"` // the points which win the game if not WinningPoints
+}
+
+// State returns a copy of everything in the game.
+func (g *Game) State() State {
+	var talon []deck.Card
+	if g.deck != nil {
+		talon = append(talon, g.deck.Current...)
+	}
+	return State{
+		Talon:          talon,
+		GameScore:      g.gameScore,
+		Hands:          [2][]deck.Card{g.Hand(Player1), g.Hand(Player2)},
+		Trump:          g.trump,
+		ClosedBy:       g.closedBy,
+		Trick:          g.trick,
+		HasTrickWon:    g.hasTrickWon,
+		Marriages:      g.marriages,
+		EmptyCardSlots: g.emptyCardSlots,
+		PlayerInTurn:   g.playerInTurn,
+		DealScore:      g.dealScore,
+		Deal:           g.dealsNum,
+		Target:         g.target,
+	}
+}
+
+// Restore returns a game which goes on from s. The next deals are shuffled as usual.
+func Restore(s State) *Game {
+	return restore(s, deck.New())
+}
+
+// RestoreWithSource returns a game which goes on from s and whose next deals are decided by src.
+func RestoreWithSource(s State, src rand.Source) *Game {
+	g := restore(s, deck.NewWithSource(src))
+	g.source = src
+	return g
+}
+
+// RestoreWithDeals returns a game which goes on from s and whose next deals are
+// decided by sources like the deals of a game created by NewWithDeals.
+func RestoreWithDeals(s State, sources func(deal int) rand.Source) *Game {
+	g := restore(s, deck.New())
+	g.deals = sources
+	return g
+}
+
+// restore returns a game which goes on from s and draws from d.
+func restore(s State, d *deck.Deck) *Game {
+	g := &Game{
+		deck:           d,
+		gameScore:      s.GameScore,
+		hands:          [2][]deck.Card{append([]deck.Card(nil), s.Hands[Player1]...), append([]deck.Card(nil), s.Hands[Player2]...)},
+		trump:          s.Trump,
+		closedBy:       s.ClosedBy,
+		trick:          s.Trick,
+		hasTrickWon:    s.HasTrickWon,
+		marriages:      s.Marriages,
+		emptyCardSlots: s.EmptyCardSlots,
+		playerInTurn:   s.PlayerInTurn,
+		dealScore:      s.DealScore,
+		dealsNum:       s.Deal,
+		target:         s.Target,
+	}
+	g.deck.Current = append([]deck.Card(nil), s.Talon...)
+	return g
+}
+
+// Clone returns a copy of the game which can be changed without changing g.
+func (g *Game) Clone() *Game {
+	return Restore(g.State())
+}
@@ -0,0 +1,76 @@
+package sixtysix
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+)
+
+func TestClone(t *testing.T) {
+	g := New()
+	g.Start()
+	g.Play(Player2, 0)
+
+	c := g.Clone()
+	if c.PlayerInTurn() != Player1 || c.Table(Player2) != g.Table(Player2) || c.TalonSize() != g.TalonSize() {
+		t.Error("Clone error!")
+	}
+
+	c.Play(Player1, 0)
+	if g.PlayerInTurn() != Player1 || g.Hand(Player1)[0] == NoCard || g.TalonSize() != 11 {
+		t.Error("Clone changed the game!")
+	}
+	if c.Hand(Player1)[0] == g.Hand(Player1)[0] && c.TalonSize() == g.TalonSize() {
+		t.Error("Clone didn't play!")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	g := Restore(State{
+		Hands:        [2][]deck.Card{cards("A♠ X♠"), cards("9♠ J♠")},
+		Trump:        card("Q♥"),
+		ClosedBy:     Nobody,
+		PlayerInTurn: Player1,
+		DealScore:    [2]int{50, 40},
+		HasTrickWon:  [2]bool{true, true},
+	})
+
+	g.Play(Player1, 0)
+	if move, err := g.Play(Player2, 0); err != nil || move.TrickWinner != Player1 || g.DealScore(Player1) != 61 {
+		t.Error("Restore error!", move, err)
+	}
+	if g.Hand(Player2)[0] != card("J♠") {
+		t.Error("Restore error: wrong hand!", g.Hand(Player2))
+	}
+}
+
+func TestMarshalState(t *testing.T) {
+	g := NewSeeded(7)
+	g.Start()
+	g.Play(Player2, 0)
+	g.Play(Player1, 0)
+
+	data, err := MarshalState(g.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := UnmarshalState(data)
+	if err != nil {
+		t.Fatal("Unmarshal error!", err)
+	}
+	restored := RestoreWithSource(s, rand.NewSource(1))
+	if fmt.Sprint(restored.State()) != fmt.Sprint(g.State()) || s.Deal != 1 {
+		t.Error("Restored state error!", s, g.State())
+	}
+
+	if _, err := UnmarshalState([]byte(`{"version":2,"state":{}}`)); err != ErrSaveVersion {
+		t.Error("Expected version error!", err)
+	}
+	s.Hands[Player1][1] = s.Trump
+	data, _ = MarshalState(s)
+	if _, err := UnmarshalState(data); err != ErrBadSave {
+		t.Error("Expected bad save!", err)
+	}
+}
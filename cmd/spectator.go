@@ -0,0 +1,212 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// spectatorQueue is how many messages can wait for a spectator.
+// A spectator who falls further behind is dropped.
+const spectatorQueue = 1024
+
+// shown is a message for a spectator.
+type shown struct {
+	t    protocol.Type
+	body interface{}
+}
+
+// spectator is a client watching a match. He cannot play. A commentator
+// sees the hands too, but everything about a deal reaches him after the deal.
+type spectator struct {
+	*player
+	commentator bool
+	held        []shown    // what the commentator sees after the deal, used only by the match
+	queue       chan shown // closed when the match has ended
+	muted       int32      // 1 if he doesn't want to get what the players say
+}
+
+// newSpectator starts sending p what the match shows him and refusing what he sends.
+func newSpectator(p *player, commentator bool) *spectator {
+	w := &spectator{player: p, commentator: commentator, queue: make(chan shown, spectatorQueue)}
+	go w.forward()
+	go w.ignore()
+	return w
+}
+
+// forward sends the messages in the queue and closes the connection after the last one.
+func (w *spectator) forward() {
+	defer w.close()
+
+	for {
+		select {
+		case s, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			if s.t != protocol.Said || atomic.LoadInt32(&w.muted) == 0 {
+				w.send(s.t, s.body)
+			}
+		case <-w.gone:
+			return
+		}
+	}
+}
+
+// ignore answers everything the spectator sends with an error until he quits.
+// He can only mute the players.
+func (w *spectator) ignore() {
+	for message := range w.inputs {
+		var mute protocol.MuteBody
+		switch {
+		case message.Type == protocol.Quit:
+			w.close()
+			return
+		case message.Type == protocol.Mute && message.Decode(&mute) == nil:
+			var muted int32
+			if mute.Muted {
+				muted = 1
+			}
+			atomic.StoreInt32(&w.muted, muted)
+		default:
+			w.proto.SendError(protocol.ReadOnly, "Spectators can only watch")
+		}
+	}
+}
+
+// show puts a message in the queue or holds it back until the deal is over if
+// the spectator is a commentator. It returns false and closes the connection
+// if the spectator is gone or too far behind.
+func (w *spectator) show(t protocol.Type, body interface{}) bool {
+	if w.isGone() {
+		return false
+	}
+	if w.commentator {
+		w.held = append(w.held, shown{t: t, body: body})
+		return true
+	}
+	return w.enqueue(shown{t: t, body: body})
+}
+
+// release puts what has been held back from the commentator in the queue.
+// It returns false like show.
+func (w *spectator) release() bool {
+	for _, s := range w.held {
+		if !w.enqueue(s) {
+			return false
+		}
+	}
+	w.held = nil
+	return true
+}
+
+// enqueue puts s in the queue. It returns false and closes the connection if it is full.
+func (w *spectator) enqueue(s shown) bool {
+	select {
+	case w.queue <- s:
+		return true
+	default:
+		w.close()
+		return false
+	}
+}
+
+// watch adds w to the spectators of m. It returns false if m has ended.
+func (m *match) watch(w *spectator) bool {
+	select {
+	case m.watchers <- w:
+		return true
+	case <-m.done:
+		return false
+	}
+}
+
+// welcome shows the new spectator w where the game is.
+func (m *match) welcome(w *spectator) {
+	if w.enqueue(shown{t: protocol.Start, body: protocol.StartBody{Seat: sixtysix.Nobody, Match: m.id, Target: m.game.Target()}}) &&
+		w.show(protocol.View, m.view(w.commentator)) {
+		m.spectators = append(m.spectators, w)
+	}
+}
+
+// view returns what the spectators see of the game. Only commentators see the hands.
+func (m *match) view(commentator bool) protocol.ViewBody {
+	deckSize := m.game.TalonSize()
+	if deckSize != 0 {
+		deckSize++ // counting the trump
+	}
+
+	view := protocol.ViewBody{
+		Trump:     m.game.Trump(),
+		DeckSize:  deckSize,
+		Closed:    m.game.IsClosed(),
+		Table:     [2]deck.Card{m.game.Table(sixtysix.Player1), m.game.Table(sixtysix.Player2)},
+		Score:     [2]int{m.game.DealScore(sixtysix.Player1), m.game.DealScore(sixtysix.Player2)},
+		GameScore: [2]int{m.game.GameScore(sixtysix.Player1), m.game.GameScore(sixtysix.Player2)},
+		Turn:      m.game.PlayerInTurn(),
+		Clock:     m.clockBody(),
+	}
+	if commentator {
+		view.Hands = [][]deck.Card{m.game.Hand(sixtysix.Player1), m.game.Hand(sixtysix.Player2)}
+	}
+	return view
+}
+
+// show sends a message with type t and the given body to the spectators.
+func (m *match) show(t protocol.Type, body interface{}) {
+	m.showEach(func(*spectator) (protocol.Type, interface{}) {
+		return t, body
+	})
+}
+
+// showView sends the view of the game to the spectators.
+func (m *match) showView() {
+	if len(m.spectators) == 0 {
+		return
+	}
+	view, full := m.view(false), m.view(true)
+	m.showEach(func(w *spectator) (protocol.Type, interface{}) {
+		if w.commentator {
+			return protocol.View, full
+		}
+		return protocol.View, view
+	})
+}
+
+// showEach sends every spectator the message returned by message
+// and forgets the spectators who are gone.
+func (m *match) showEach(message func(w *spectator) (protocol.Type, interface{})) {
+	spectators := m.spectators[:0]
+	for _, w := range m.spectators {
+		if w.show(message(w)) {
+			spectators = append(spectators, w)
+		}
+	}
+	m.spectators = spectators
+}
+
+// releaseDeal shows the commentators the deal which is over.
+func (m *match) releaseDeal() {
+	spectators := m.spectators[:0]
+	for _, w := range m.spectators {
+		if w.release() {
+			spectators = append(spectators, w)
+		}
+	}
+	m.spectators = spectators
+}
+
+// endShow tells the spectators that the match has ended if the game isn't over
+// and lets them go after they have seen everything.
+func (m *match) endShow() {
+	if !m.game.IsOver() {
+		m.show(protocol.OpponentLeft, nil)
+	}
+	m.releaseDeal()
+	for _, w := range m.spectators {
+		close(w.queue)
+	}
+	m.spectators = nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+	"github.com/DanislavKirov/sixtySix/cmd/deck"
+	"github.com/DanislavKirov/sixtySix/cmd/protocol"
+	"github.com/DanislavKirov/sixtySix/cmd/sixtysix"
+)
+
+// duel is a game between two strategies played in the same process.
+// The views of the strategies are built from the same messages the server
+// would send, so the strategies cannot tell the difference.
+type duel struct {
+	game       *sixtysix.Game
+	sources    func(deal int) rand.Source
+	strategies [2]bot.Strategy
+	views      [2]*bot.View
+	stats      [2]*Stats
+	dealOver   bool
+	mirror     *duel // plays every deal again with the seats swapped if not nil
+}
+
+// dealSources returns the sources of the deals of the game with seed.
+// Every deal has its own source, so it can be played again with the same cards.
+func dealSources(seed int64) func(deal int) rand.Source {
+	return func(deal int) rand.Source {
+		return rand.NewSource(seed<<8 | int64(deal)) // a game has far fewer than 256 deals
+	}
+}
+
+// newDuel returns a duel between s1 on Player1 and s2 on Player2 with deals decided by seed.
+func newDuel(seed int64, s1, s2 bot.Strategy, stats1, stats2 *Stats) *duel {
+	sources := dealSources(seed)
+	return &duel{
+		game:       sixtysix.NewWithDeals(sources),
+		sources:    sources,
+		strategies: [2]bot.Strategy{s1, s2},
+		views:      [2]*bot.View{bot.NewView(), bot.NewView()},
+		stats:      [2]*Stats{stats1, stats2},
+	}
+}
+
+// sendTo passes a message with type t and the given body to the view of player.
+func (d *duel) sendTo(player int, t protocol.Type, body interface{}) {
+	message, err := protocol.NewMessage(t, body)
+	if err != nil {
+		panic(err)
+	}
+	if err := d.views[player].Observe(message); err != nil {
+		panic(err)
+	}
+	if o, ok := d.strategies[player].(bot.Observer); ok {
+		o.Observe(message) // an engine which has failed plays the first legal card
+	}
+}
+
+// sendAll passes a message with type t and the given body to both views.
+func (d *duel) sendAll(t protocol.Type, body interface{}) {
+	d.sendTo(sixtysix.Player1, t, body)
+	d.sendTo(sixtysix.Player2, t, body)
+}
+
+// state returns what player can see now, the same as the server sends.
+func (d *duel) state(player int) protocol.StateBody {
+	deckSize := d.game.TalonSize()
+	if deckSize != 0 {
+		deckSize++ // counting the trump
+	}
+
+	return protocol.StateBody{
+		Hand:      d.game.Hand(player),
+		Trump:     d.game.Trump(),
+		DeckSize:  deckSize,
+		Closed:    d.game.IsClosed(),
+		Table:     d.game.Table(sixtysix.OpponentOf(player)),
+		Score:     d.game.DealScore(player),
+		GameScore: [2]int{d.game.GameScore(sixtysix.Player1), d.game.GameScore(sixtysix.Player2)},
+		Turn:      d.game.PlayerInTurn(),
+	}
+}
+
+// sendState passes to each view what its player can see now.
+func (d *duel) sendState() {
+	d.sendTo(sixtysix.Player1, protocol.State, d.state(sixtysix.Player1))
+	d.sendTo(sixtysix.Player2, protocol.State, d.state(sixtysix.Player2))
+}
+
+// endDeal informs the players about the end of a deal which was closed by closedBy and counts it.
+func (d *duel) endDeal(winner, pts, closedBy int) {
+	score := [2]int{d.game.GameScore(sixtysix.Player1), d.game.GameScore(sixtysix.Player2)}
+	d.sendAll(protocol.DealResult, protocol.ResultBody{Winner: winner, Points: pts, Score: &score})
+	d.stats[sixtysix.Player1].Deals++
+	d.stats[sixtysix.Player2].Deals++
+	d.dealOver = true
+
+	if closedBy != sixtysix.Nobody {
+		d.stats[closedBy].Closes++
+		if winner == closedBy {
+			d.stats[closedBy].ClosesWon++
+		}
+	}
+	if d.game.IsOver() {
+		d.sendAll(protocol.GameResult, protocol.ResultBody{Winner: d.game.Winner(), Points: score[d.game.Winner()], Score: &score})
+	}
+}
+
+// run plays the game until it is over and returns the winner. If there is
+// a mirror, it plays every deal again from the same state before the deal counts.
+func (d *duel) run() int {
+	d.game.Start()
+	d.start()
+	for !d.game.IsOver() {
+		if d.mirror != nil {
+			d.mirror.replay(d.game)
+		}
+		d.playDeal()
+	}
+
+	winner := d.game.Winner()
+	d.stats[winner].Wins++
+	for _, player := range [2]int{sixtysix.Player1, sixtysix.Player2} {
+		d.stats[player].Games++
+		d.stats[player].GamePoints.Add(float64(d.game.GameScore(player)))
+	}
+	return winner
+}
+
+// replay plays the deal game is about to play with the strategies of d,
+// which sit on the other seats. Only the statistics of the deal are counted.
+func (d *duel) replay(game *sixtysix.Game) {
+	d.game = sixtysix.RestoreWithDeals(game.State(), d.sources)
+	d.views = [2]*bot.View{bot.NewView(), bot.NewView()}
+	d.start()
+	d.playDeal()
+}
+
+// start tells the players their seats and what they can see.
+func (d *duel) start() {
+	d.sendTo(sixtysix.Player1, protocol.Start, protocol.StartBody{Seat: sixtysix.Player1})
+	d.sendTo(sixtysix.Player2, protocol.Start, protocol.StartBody{Seat: sixtysix.Player2})
+	d.sendState()
+}
+
+// playDeal plays until the current deal is over.
+func (d *duel) playDeal() {
+	d.dealOver = false
+	mistakes := 0
+	for !d.dealOver {
+		player := d.game.PlayerInTurn()
+		action := bot.Action{Type: bot.Play, Card: d.firstLegalCard(player)}
+		if mistakes < bot.MaxMistakes {
+			before := fallbacks(d.strategies[player])
+			action = d.strategies[player].Act(d.views[player])
+			d.stats[player].Fallbacks += fallbacks(d.strategies[player]) - before
+		}
+
+		if d.act(player, action) {
+			mistakes = 0
+		} else {
+			d.stats[player].Mistakes++
+			mistakes++
+			d.sendTo(player, protocol.Error, protocol.ErrorBody{Code: protocol.NotPossible, Message: "Not possible"})
+		}
+	}
+}
+
+// fallbacks returns how many moves have been played for s because it has failed
+// if it is an engine, otherwise 0.
+func fallbacks(s bot.Strategy) int {
+	if f, ok := s.(interface {
+		Fallbacks() int
+	}); ok {
+		return f.Fallbacks()
+	}
+	return 0
+}
+
+// firstLegalCard returns the first card player can play.
+func (d *duel) firstLegalCard(player int) deck.Card {
+	return d.game.Hand(player)[d.game.LegalCards(player)[0]]
+}
+
+// act does what player has decided. It returns false if it isn't possible.
+func (d *duel) act(player int, action bot.Action) bool {
+	switch action.Type {
+	case bot.Close:
+		if !d.game.Close(player) {
+			return false
+		}
+		d.sendAll(protocol.Closed, protocol.SeatBody{Seat: player})
+	case bot.Exchange:
+		if !d.game.Exchange(player) {
+			return false
+		}
+		d.sendAll(protocol.Exchanged, protocol.SeatBody{Seat: player})
+		d.stats[player].Exchanges++
+	case bot.Stop:
+		closedBy := d.game.ClosedBy()
+		success, winner, pts := d.game.Stop(player)
+		if !success {
+			return false
+		}
+		d.stats[player].Stops++
+		if winner == player {
+			d.stats[player].StopsWon++
+		}
+		d.endDeal(winner, pts, closedBy)
+	default:
+		if !d.play(player, action.Card) {
+			return false
+		}
+	}
+
+	if !d.game.IsOver() {
+		d.sendState()
+	}
+	return true
+}
+
+// play plays card from the hand of player. It returns false if it cannot be played.
+func (d *duel) play(player int, card deck.Card) bool {
+	cardIdx := -1
+	for idx, c := range d.game.Hand(player) {
+		if c == card && c != sixtysix.NoCard {
+			cardIdx = idx
+		}
+	}
+
+	closedBy := d.game.ClosedBy()
+	move, err := d.game.Play(player, cardIdx)
+	if err != nil {
+		return false
+	}
+
+	d.sendAll(protocol.Played, protocol.PlayedBody{Seat: player, Card: move.Card, Marriage: move.Marriage})
+	if move.Marriage != 0 {
+		d.stats[player].Marriages++
+	}
+	if move.TrickWinner != sixtysix.Nobody {
+		d.sendAll(protocol.TrickResult, protocol.ResultBody{Winner: move.TrickWinner, Points: move.TrickPoints})
+	}
+	if move.DealWinner != sixtysix.Nobody {
+		d.endDeal(move.DealWinner, move.DealPoints, closedBy)
+	}
+	return true
+}
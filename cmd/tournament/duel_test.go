@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+)
+
+func TestTournament(t *testing.T) {
+	run := func(workers int) (*Stats, *Stats) {
+		statsA, statsB := &Stats{}, &Stats{}
+		if err := tournament("advanced", "random", statsA, statsB, 20, 66, 0, workers); err != nil {
+			t.Fatal(err)
+		}
+		return statsA, statsB
+	}
+
+	statsA, statsB := run(1)
+	if statsA.Games != 20 || statsB.Games != 20 || statsA.Wins+statsB.Wins != 20 || statsA.Deals != statsB.Deals {
+		t.Error("Tournament error!", statsA, statsB)
+	}
+	if statsA.Mistakes != 0 || statsB.Mistakes != 0 {
+		t.Error("Strategies made mistakes!", statsA.Mistakes, statsB.Mistakes)
+	}
+	if statsA.Wins <= statsB.Wins {
+		t.Error("Expected advanced to beat random!", statsA.Wins, statsB.Wins)
+	}
+
+	againA, againB := run(4)
+	if *againA != *statsA || *againB != *statsB {
+		t.Error("Expected the same results with the same seeds!", againA, statsA)
+	}
+}
+
+func TestTournamentStopsOnError(t *testing.T) {
+	statsA, statsB := &Stats{}, &Stats{}
+	if err := tournament("advanced", "exec:/nonexistent/engine", statsA, statsB, 20, 66, 0, 4); err == nil {
+		t.Error("Expected the engine not to start!")
+	}
+	if statsA.Games != 0 || statsB.Games != 0 {
+		t.Error("Expected no games!", statsA.Games, statsB.Games)
+	}
+}
+
+func TestDuelSameCards(t *testing.T) {
+	a, _ := newStrategy("greedy", 1, 0)
+	b, _ := newStrategy("greedy", 2, 0)
+	statsA, statsB := &Stats{}, &Stats{}
+	d := newDuel(6, a, b, statsA, statsB)
+	d.mirror = newDuel(6, b, a, statsB, statsA)
+	d.game.Start()
+	d.start()
+	start := fmt.Sprint(d.game.State())
+
+	d.mirror.replay(d.game)
+	if fmt.Sprint(d.game.State()) != start || statsA.Deals != 1 || statsB.Deals != 1 || statsA.Games != 0 {
+		t.Error("Replay error!", statsA, statsB)
+	}
+
+	// the next deal has the same cards whatever has happened in the first one
+	d.playDeal()
+	if d.mirror.game.Trump() != d.game.Trump() || d.mirror.game.TalonSize() != d.game.TalonSize() {
+		t.Error("Expected the same cards!", d.mirror.game.Trump(), d.game.Trump())
+	}
+}
+
+// failing is a strategy which fails every move, like a broken engine,
+// and has a legal card played for it.
+type failing struct {
+	bot.Strategy
+	fails int
+}
+
+func (f *failing) Act(v *bot.View) bot.Action {
+	f.fails++
+	return f.Strategy.Act(v)
+}
+
+func (f *failing) Fallbacks() int {
+	return f.fails
+}
+
+func TestDuelFallbacks(t *testing.T) {
+	a, _ := newStrategy("greedy", 1, 0)
+	b, _ := newStrategy("greedy", 2, 0)
+	f := &failing{Strategy: a}
+	statsA, statsB := &Stats{}, &Stats{}
+	d := newDuel(6, f, b, statsA, statsB)
+	d.game.Start()
+	d.start()
+	d.playDeal()
+	if statsA.Fallbacks == 0 || statsA.Fallbacks != f.fails || statsB.Fallbacks != 0 {
+		t.Error("Fallbacks error!", statsA.Fallbacks, f.fails, statsB.Fallbacks)
+	}
+}
@@ -0,0 +1,176 @@
+// Command tournament plays many games between two bot strategies in the same
+// process, without a server, and reports how well each of them has played.
+//
+// Every deal is played twice from the same state with the same cards: once
+// with each strategy on each seat, so both get the same cards and the luck of
+// the deals evens out. The game goes on from the first time, which decides the
+// wins and the game points. The first strategy sits on the first seat in the
+// games with an even seed.
+//
+//	tournament -a expert -b advanced -games 1000 -seed 1
+//
+// An engine, a bot in another program, is named by its command after exec:
+//
+//	tournament -a "exec:./mybot -level 3" -b advanced
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/DanislavKirov/sixtySix/cmd/bot"
+	"github.com/DanislavKirov/sixtySix/cmd/engine"
+)
+
+// newStrategy returns the named strategy which decides at random with seed.
+// The budget of MonteCarlo is counted in deals only, so the results can be repeated.
+// A name with engine.Prefix starts an engine.
+func newStrategy(name string, seed int64, iterations int) (bot.Strategy, error) {
+	if strings.HasPrefix(name, engine.Prefix) {
+		return engine.StartNamed(name)
+	}
+
+	s, err := bot.New(name, rand.New(rand.NewSource(seed)))
+	if mc, ok := s.(*bot.MonteCarlo); ok {
+		if iterations <= 0 {
+			iterations = bot.DefaultIterations // it would never stop without a time limit
+		}
+		mc.Iterations, mc.Duration = iterations, 0
+	}
+	return s, err
+}
+
+// pair is what both strategies have done in a game and in its deals played with swapped seats.
+type pair struct {
+	a, b Stats
+}
+
+// playPair plays a game between the strategies named a and b with the given seed
+// and every deal of it again with swapped seats. The strategies are created for
+// the game, and again for the replayed deals, so the result doesn't depend on the other games.
+// It returns an error if a strategy cannot be created.
+func playPair(a, b string, seed int64, iterations int) (pair, error) {
+	var p pair
+	var strategies [4]bot.Strategy // a and b, then a and b for the replayed deals
+	for i := range strategies {
+		name := a
+		if i%2 == 1 {
+			name = b
+		}
+		s, err := newStrategy(name, seed+int64(i), iterations)
+		defer closeStrategy(s)
+		if err != nil {
+			return p, err
+		}
+		strategies[i] = s
+	}
+
+	d := newDuel(seed, strategies[0], strategies[1], &p.a, &p.b)
+	d.mirror = newDuel(seed, strategies[3], strategies[2], &p.b, &p.a)
+	if seed&1 == 1 {
+		d = newDuel(seed, strategies[1], strategies[0], &p.b, &p.a)
+		d.mirror = newDuel(seed, strategies[2], strategies[3], &p.a, &p.b)
+	}
+	d.run()
+	return p, nil
+}
+
+// closeStrategy stops the engine behind s if there is one.
+func closeStrategy(s bot.Strategy) {
+	if e, ok := s.(*engine.Engine); ok && e != nil {
+		if err := e.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, e.Name, err)
+		}
+		e.Close()
+	}
+}
+
+// played is a game played by a worker or why it couldn't be played.
+type played struct {
+	pair
+	err error
+}
+
+// tournament plays the given number of games between the strategies named a and b
+// on the given number of workers. The seeds of the games start from seed.
+// It stops at the first game which cannot be played and returns why.
+func tournament(a, b string, statsA, statsB *Stats, games int, seed int64, iterations, workers int) error {
+	seeds := make(chan int64)
+	results := make(chan played)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				p, err := playPair(a, b, seed, iterations)
+				results <- played{p, err}
+			}
+		}()
+	}
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		defer close(seeds)
+		for i := 0; i < games; i++ {
+			select {
+			case seeds <- seed + int64(i):
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var err error
+	for r := range results {
+		if err != nil {
+			continue // the games which have already started
+		}
+		if r.err != nil {
+			err = r.err
+			close(stop)
+			continue
+		}
+		statsA.Merge(&r.a)
+		statsB.Merge(&r.b)
+	}
+	return err
+}
+
+func main() {
+	names := strings.Join(bot.Names, ", ")
+	nameA := flag.String("a", "advanced", "the first strategy: "+names+" or exec:command")
+	nameB := flag.String("b", "greedy", "the second strategy: "+names+" or exec:command")
+	games := flag.Int("games", 1000, "how many games to play, each deal of them twice with swapped seats")
+	seed := flag.Int64("seed", 1, "the seed of the first game, the next ones use the next numbers")
+	iterations := flag.Int("iterations", 100, "the deals the expert strategy tries before each move")
+	workers := flag.Int("workers", runtime.NumCPU(), "how many games to play at the same time")
+	flag.Parse()
+
+	for _, name := range []string{*nameA, *nameB} {
+		if _, err := bot.New(name, nil); err != nil && !strings.HasPrefix(name, engine.Prefix) {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	statsA, statsB := &Stats{Name: "A: " + *nameA}, &Stats{Name: "B: " + *nameB}
+	if err := tournament(*nameA, *nameB, statsA, statsB, *games, *seed, *iterations, *workers); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d games, 95%% confidence intervals\n\n", statsA.Games)
+	statsA.Report(os.Stdout)
+	fmt.Println()
+	statsB.Report(os.Stdout)
+}
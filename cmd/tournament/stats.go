@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// z is the quantile of the normal distribution for 95% confidence intervals.
+const z = 1.96
+
+// Sample collects numbers to find their mean.
+type Sample struct {
+	N     int
+	Sum   float64
+	SumSq float64
+}
+
+// Add adds x to the sample.
+func (s *Sample) Add(x float64) {
+	s.N++
+	s.Sum += x
+	s.SumSq += x * x
+}
+
+// Mean returns the mean of the sample and the half-width of its 95% confidence interval.
+func (s *Sample) Mean() (float64, float64) {
+	if s.N == 0 {
+		return 0, 0
+	}
+	mean := s.Sum / float64(s.N)
+	if s.N == 1 {
+		return mean, 0
+	}
+	variance := (s.SumSq - float64(s.N)*mean*mean) / float64(s.N-1)
+	return mean, z * math.Sqrt(math.Max(variance, 0)/float64(s.N))
+}
+
+// Wilson returns the 95% Wilson score interval of the proportion of k successes in n trials.
+func Wilson(k, n int) (float64, float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	p := float64(k) / float64(n)
+	nf := float64(n)
+	center := (p + z*z/(2*nf)) / (1 + z*z/nf)
+	half := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / (1 + z*z/nf)
+	return center - half, center + half
+}
+
+// Stats is what a strategy has done in a tournament.
+type Stats struct {
+	Name       string
+	Games      int
+	Wins       int
+	GamePoints Sample // the game points at the end of each game
+	Deals      int
+	Marriages  int
+	Exchanges  int
+	Closes     int
+	ClosesWon  int
+	Stops      int
+	StopsWon   int
+	Mistakes   int // actions which weren't possible
+	Fallbacks  int // moves played for an engine which has failed
+}
+
+// Merge adds what is counted in other to s.
+func (s *Stats) Merge(other *Stats) {
+	s.Games += other.Games
+	s.Wins += other.Wins
+	s.GamePoints.N += other.GamePoints.N
+	s.GamePoints.Sum += other.GamePoints.Sum
+	s.GamePoints.SumSq += other.GamePoints.SumSq
+	s.Deals += other.Deals
+	s.Marriages += other.Marriages
+	s.Exchanges += other.Exchanges
+	s.Closes += other.Closes
+	s.ClosesWon += other.ClosesWon
+	s.Stops += other.Stops
+	s.StopsWon += other.StopsWon
+	s.Mistakes += other.Mistakes
+	s.Fallbacks += other.Fallbacks
+}
+
+// rate returns the proportion of k in n in percents and its confidence interval.
+func rate(k, n int) string {
+	if n == 0 {
+		return "-"
+	}
+	low, high := Wilson(k, n)
+	return fmt.Sprintf("%5.1f%% [%.1f, %.1f] (%d/%d)", 100*float64(k)/float64(n), 100*low, 100*high, k, n)
+}
+
+// Report writes the statistics of s to w.
+func (s *Stats) Report(w io.Writer) {
+	mean, half := s.GamePoints.Mean()
+	fmt.Fprintf(w, "%s\n", s.Name)
+	fmt.Fprintf(w, "  wins:           %s\n", rate(s.Wins, s.Games))
+	fmt.Fprintf(w, "  game points:    %.2f ± %.2f\n", mean, half)
+	fmt.Fprintf(w, "  marriages/deal: %.2f (%d/%d)\n", ratio(s.Marriages, s.Deals), s.Marriages, s.Deals)
+	fmt.Fprintf(w, "  exchanges/deal: %.2f (%d/%d)\n", ratio(s.Exchanges, s.Deals), s.Exchanges, s.Deals)
+	fmt.Fprintf(w, "  closes won:     %s\n", rate(s.ClosesWon, s.Closes))
+	fmt.Fprintf(w, "  stops won:      %s\n", rate(s.StopsWon, s.Stops))
+	if s.Mistakes != 0 {
+		fmt.Fprintf(w, "  mistakes:       %d\n", s.Mistakes)
+	}
+	if s.Fallbacks != 0 {
+		fmt.Fprintf(w, "  fallbacks:      %d\n", s.Fallbacks)
+	}
+}
+
+// ratio returns k/n or 0 if n is 0.
+func ratio(k, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(k) / float64(n)
+}
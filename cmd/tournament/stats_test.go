@@ -0,0 +1,27 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMean(t *testing.T) {
+	var s Sample
+	for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		s.Add(x)
+	}
+	mean, half := s.Mean()
+	if mean != 5 || math.Abs(half-z*math.Sqrt(32.0/7/8)) > 1e-9 {
+		t.Error("Mean error!", mean, half)
+	}
+}
+
+func TestWilson(t *testing.T) {
+	low, high := Wilson(50, 100)
+	if math.Abs(low-0.4038) > 1e-3 || math.Abs(high-0.5962) > 1e-3 {
+		t.Error("Wilson error!", low, high)
+	}
+	if low, high := Wilson(0, 10); low != 0 || high <= 0 || high >= 1 {
+		t.Error("Wilson error!", low, high)
+	}
+}